@@ -2,13 +2,27 @@ package app
 
 import (
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/config"
 	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/ui/theme"
 )
 
-func New(dbClient db.DB) tea.Model {
-	return initialModel(dbClient)
+// Options configure the TUI at startup.
+type Options struct {
+	TimeZone string // zone for timestamptz values; empty follows the server
+	Theme    theme.Theme
+
+	ProfileName string
+	Profile     config.Profile // prefills the form; Production guards writes
+	ReadOnly    bool           // read-only session, write keys disabled
+
+	AuditLog string // JSON lines file every write is appended to
+}
+
+func New(dbClient db.DB, opts Options) tea.Model {
+	return initialModel(dbClient, opts)
 }
 
-func NewProgram(dbClient db.DB) *tea.Program {
-	return tea.NewProgram(New(dbClient))
+func NewProgram(dbClient db.DB, opts Options) *tea.Program {
+	return tea.NewProgram(New(dbClient, opts))
 }
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/audit"
+	"github.com/mattn/go-runewidth"
+)
+
+// auditShown is how many of the newest log entries the viewer loads.
+const auditShown = 500
+
+type auditLoadedMsg struct {
+	entries []audit.Entry
+	err     error
+}
+
+func auditLoadCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := audit.Read(path, auditShown)
+		return auditLoadedMsg{entries: entries, err: err}
+	}
+}
+
+// logged takes the outcome of a write. A failure to append to the audit log
+// does not undo the write, so it is split off and shown on the banner until
+// a later write is logged; the write itself counts as done.
+func (m *Model) logged(err error) error {
+	var logErr *audit.LogError
+	if errors.As(err, &logErr) {
+		m.auditErr = logErr.Err
+		return nil
+	}
+	if err == nil {
+		m.auditErr = nil
+	}
+	return err
+}
+
+// ----- Audit log viewer -----
+
+func (m Model) openAudit() (tea.Model, tea.Cmd) {
+	if m.opts.AuditLog == "" {
+		m.status = "There is no audit log file: no config directory was found. Start with --audit-log to keep one."
+		return m, nil
+	}
+	m.auditFrom = m.mode
+	m.loading = true
+	m.status = "Reading " + m.opts.AuditLog + "..."
+	return m, auditLoadCmd(m.opts.AuditLog)
+}
+
+func (m Model) auditLoaded(msg auditLoadedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.status = "Cannot read the audit log: " + msg.err.Error()
+		return m, nil
+	}
+	// newest first
+	m.auditEntries = make([]audit.Entry, len(msg.entries))
+	for i, e := range msg.entries {
+		m.auditEntries[len(msg.entries)-1-i] = e
+	}
+	m.auditCursor = 0
+	m.auditDetail = false
+	m.mode = modeAudit
+	m.status = fmt.Sprintf("%d write(s) in %s. Enter shows the statement, Esc goes back.", len(m.auditEntries), m.opts.AuditLog)
+	return m, nil
+}
+
+func (m Model) updateAuditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+	case "esc", "b", "q":
+		if m.auditDetail {
+			m.auditDetail = false
+			return m, nil
+		}
+		m.mode = m.auditFrom
+		m.status = "Back."
+		return m, nil
+	case "up", "k":
+		if m.auditCursor > 0 {
+			m.auditCursor--
+		}
+	case "down", "j":
+		if m.auditCursor < len(m.auditEntries)-1 {
+			m.auditCursor++
+		}
+	case "enter":
+		m.auditDetail = !m.auditDetail && len(m.auditEntries) > 0
+	case "r":
+		return m.openAudit()
+	}
+	return m, nil
+}
+
+func (m Model) viewAudit() string {
+	s := m.theme.Title.Render("Audit log") + "\n\n"
+
+	if len(m.auditEntries) == 0 {
+		s += "  (no writes logged yet)\n"
+	} else if m.auditDetail {
+		s += m.auditEntryDetail(m.auditEntries[m.auditCursor])
+	} else {
+		// keep the cursor on screen
+		start, end := 0, len(m.auditEntries)
+		if visible := m.height - 8; m.height > 0 && visible > 0 && end > visible {
+			start = m.auditCursor - visible/2
+			if start < 0 {
+				start = 0
+			}
+			end = start + visible
+			if end > len(m.auditEntries) {
+				end = len(m.auditEntries)
+				start = end - visible
+			}
+		}
+
+		width := m.width - 2
+		if width <= 0 {
+			width = 120
+		}
+		for i := start; i < end; i++ {
+			cursor := "  "
+			if i == m.auditCursor {
+				cursor = "> "
+			}
+			line := runewidth.Truncate(auditSummary(m.auditEntries[i]), width, "…")
+			if m.auditEntries[i].Error != "" {
+				line = m.theme.Error.Render(line)
+			}
+			s += cursor + line + "\n"
+		}
+	}
+
+	s += "\n" + m.theme.Status.Render(m.status) + "\n"
+	s += "\n" + m.theme.Help.Render("↑/↓ to move, Enter to show a statement, 'r' to reload, Esc to go back.") + "\n"
+	return s
+}
+
+// auditSummary is the one-line form of an entry in the list.
+func auditSummary(e audit.Entry) string {
+	who := e.User + "@" + e.Database
+	if e.Profile != "" {
+		who = e.Profile + " " + who
+	}
+	outcome := fmt.Sprintf("%d row(s)", e.Affected)
+	if e.Error != "" {
+		outcome = "failed"
+	}
+	sql := strings.Join(strings.Fields(e.SQL), " ")
+	return fmt.Sprintf("%s  %s  %-9s  %s", e.Time.Local().Format("2006-01-02 15:04:05"), who, outcome, sql)
+}
+
+func (m Model) auditEntryDetail(e audit.Entry) string {
+	s := fmt.Sprintf("Time:     %s\n", e.Time.Local().Format("2006-01-02 15:04:05.000 MST"))
+	if e.Profile != "" {
+		s += fmt.Sprintf("Profile:  %s\n", e.Profile)
+	}
+	s += fmt.Sprintf("Database: %s\nUser:     %s\nAffected: %d row(s)\n", e.Database, e.User, e.Affected)
+	if e.Error != "" {
+		s += m.theme.Error.Render("Error:    "+e.Error) + "\n"
+	}
+	s += "\n" + e.SQL + "\n"
+	for i, p := range e.Params {
+		data, _ := json.Marshal(p)
+		s += fmt.Sprintf("  $%d = %s\n", i+1, runewidth.Truncate(string(data), 200, "…"))
+	}
+	return s
+}
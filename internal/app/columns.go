@@ -0,0 +1,158 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/config"
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// ----- Column layout -----
+
+type chooserItem struct {
+	name    string
+	typ     string
+	visible bool
+}
+
+func (m Model) layoutKey() string {
+	return config.LayoutKey(m.dbInput.Value(), m.selectedTable)
+}
+
+// applyLayout sets the projection from the saved layout of the selected
+// table, if there is one.
+func (m *Model) applyLayout() {
+	m.visibleColumns, m.hiddenColumns = nil, nil
+
+	layout, ok := m.layouts[m.layoutKey()]
+	if !ok {
+		return
+	}
+	m.visibleColumns, m.hiddenColumns = layout.Apply(db.ColumnNames(m.allColumns))
+	if len(m.visibleColumns) == 0 {
+		// never select nothing, even if the saved layout says so
+		m.visibleColumns, m.hiddenColumns = nil, nil
+	}
+}
+
+// ----- Column chooser -----
+
+func (m Model) openChooser() (tea.Model, tea.Cmd) {
+	if len(m.allColumns) == 0 {
+		return m, nil
+	}
+
+	types := make(map[string]string, len(m.allColumns))
+	for _, c := range m.allColumns {
+		types[c.Name] = c.TypeName
+	}
+
+	visible, hidden := m.visibleColumns, m.hiddenColumns
+	if visible == nil {
+		visible = db.ColumnNames(m.allColumns)
+	}
+
+	m.chooser = nil
+	for _, name := range visible {
+		m.chooser = append(m.chooser, chooserItem{name: name, typ: types[name], visible: true})
+	}
+	for _, name := range hidden {
+		m.chooser = append(m.chooser, chooserItem{name: name, typ: types[name]})
+	}
+	m.chooserCursor = 0
+	m.mode = modeColumns
+	m.status = "Space to show/hide, K/J (shift+↑/↓) to move, Enter to apply, 'R' to reset, Esc to cancel."
+	return m, nil
+}
+
+func (m Model) updateColumnsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+
+	case "esc", "q":
+		m.mode = modeRows
+		m.status = "Column changes discarded."
+		return m, nil
+
+	case "up", "k":
+		if m.chooserCursor > 0 {
+			m.chooserCursor--
+		}
+	case "down", "j":
+		if m.chooserCursor < len(m.chooser)-1 {
+			m.chooserCursor++
+		}
+
+	case "shift+up", "K":
+		if i := m.chooserCursor; i > 0 {
+			m.chooser[i-1], m.chooser[i] = m.chooser[i], m.chooser[i-1]
+			m.chooserCursor--
+		}
+	case "shift+down", "J":
+		if i := m.chooserCursor; i < len(m.chooser)-1 {
+			m.chooser[i+1], m.chooser[i] = m.chooser[i], m.chooser[i+1]
+			m.chooserCursor++
+		}
+
+	case " ", "x":
+		m.chooser[m.chooserCursor].visible = !m.chooser[m.chooserCursor].visible
+
+	case "R":
+		delete(m.layouts, m.layoutKey())
+		return m.saveLayout("Column layout reset.")
+
+	case "enter":
+		var layout config.Layout
+		for _, item := range m.chooser {
+			if item.visible {
+				layout.Visible = append(layout.Visible, item.name)
+			} else {
+				layout.Hidden = append(layout.Hidden, item.name)
+			}
+		}
+		if len(layout.Visible) == 0 {
+			m.status = "At least one column must stay visible."
+			return m, nil
+		}
+		m.layouts[m.layoutKey()] = layout
+		return m.saveLayout(fmt.Sprintf("Showing %d of %d columns.", len(layout.Visible), len(m.chooser)))
+	}
+
+	return m, nil
+}
+
+// saveLayout persists the layouts, applies the selected table's layout and
+// reloads the page with the new projection.
+func (m Model) saveLayout(done string) (tea.Model, tea.Cmd) {
+	m.applyLayout()
+	m.colOffset = 0
+	m.clearMarks()
+	m.status = done
+	if err := m.layouts.Save(); err != nil {
+		m.status += " (not saved: " + err.Error() + ")"
+	}
+
+	m.loading = true
+	return m, fetchRowsCmd(m.dbClient, m.selectedTable, m.queryOptions(m.offset))
+}
+
+func (m Model) viewColumns() string {
+	s := m.theme.Title.Render("Columns of "+m.selectedTable) + "\n\n"
+
+	for i, item := range m.chooser {
+		cursor := "  "
+		if i == m.chooserCursor {
+			cursor = "> "
+		}
+		check := "[ ]"
+		if item.visible {
+			check = "[x]"
+		}
+		s += fmt.Sprintf("%s%s %s  (%s)\n", cursor, check, item.name, item.typ)
+	}
+
+	s += "\n" + m.theme.Status.Render(m.status) + "\n"
+	return s
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/ui/table"
+)
+
+// deleteConfirmYes is the largest delete that 'y' may confirm; bigger ones
+// need the table name typed out.
+const deleteConfirmYes = 10
+
+// ----- Messages -----
+
+type deletePreviewMsg struct {
+	where string
+	page  db.RowPage
+	err   error
+}
+
+// ----- Commands -----
+
+// deletePreviewCmd counts the rows a DELETE would remove and fetches the
+// first page of them.
+func deletePreviewCmd(client db.DB, tableName, where string, opts db.QueryOptions) tea.Cmd {
+	return func() tea.Msg {
+		// preview exactly what DeleteRows will match: the typed clause
+		// alone, without the structured filter or search
+		opts.Filter, opts.Where = where, nil
+		opts.Offset = 0
+		page, err := client.FetchRows(context.Background(), tableName, opts)
+		return deletePreviewMsg{where: where, page: page, err: err}
+	}
+}
+
+// ----- WHERE prompt -----
+
+func (m Model) openDelete() (tea.Model, tea.Cmd) {
+	m.editingDelete = true
+	m.editingFilter = false
+	// start from the filter and search: what you see is what you delete
+	m.deleteInput.SetValue(m.shownWhere())
+	m.deleteInput.CursorEnd()
+	m.deleteInput.Focus()
+	m.status = "Enter SQL WHERE clause for DELETE (without 'WHERE'). Enter to preview the rows, Esc to cancel."
+	return m, nil
+}
+
+// shownWhere is the WHERE clause of the rows on screen, the filter and the
+// search together, as SQL text; "" when every row is shown.
+func (m Model) shownWhere() string {
+	if m.filter == "" {
+		if e := m.whereExpr(); e != nil {
+			return e.String()
+		}
+		return ""
+	}
+	if s := m.searchExpr(); s != nil {
+		return "(" + m.filter + ") AND " + s.String()
+	}
+	return m.filter
+}
+
+func (m Model) updateDeleteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.editingDelete = false
+		m.status = "Delete cancelled. Press 'd' to delete again."
+		return m, nil
+
+	case "enter":
+		where := strings.TrimSpace(m.deleteInput.Value())
+		if where == "" {
+			m.status = "WHERE clause cannot be empty for DELETE."
+			return m, nil
+		}
+		m.loading = true
+		m.status = "Counting matching rows..."
+		return m, deletePreviewCmd(m.dbClient, m.selectedTable, where, m.queryOptions(0))
+	}
+
+	var cmd tea.Cmd
+	m.deleteInput, cmd = m.deleteInput.Update(msg)
+	return m, cmd
+}
+
+// ----- Confirmation -----
+
+func (m Model) deleteConfirmHint() string {
+	n := m.deletePreview.TotalRows
+	if n <= deleteConfirmYes {
+		return fmt.Sprintf("%d row(s) will be deleted. Type 'y' or the table name and press Enter to delete, Esc to cancel.", n)
+	}
+	return fmt.Sprintf("%d rows will be deleted. Type %q and press Enter to delete, Esc to cancel.", n, m.selectedTable)
+}
+
+func (m Model) updateDeleteConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+	case "esc":
+		m.mode = modeRows
+		m.deletePreview = nil
+		m.status = "Delete cancelled. Press 'd' to delete again."
+		return m, nil
+
+	case "enter":
+		n := m.deletePreview.TotalRows
+		answer := strings.TrimSpace(m.deleteConfirm.Value())
+		if n == 0 || !(answer == m.selectedTable || (answer == "y" && n <= deleteConfirmYes)) {
+			m.status = m.deleteConfirmHint()
+			return m, nil
+		}
+		m.mode = modeRows
+		m.deletePreview = nil
+		m.loading = true
+		m.status = "Deleting rows..."
+		where := m.deleteWhere
+		return m.guardWrite(fmt.Sprintf("delete %d row(s) from %s", n, m.selectedTable), func() tea.Cmd {
+			return deleteRowsCmd(m.dbClient, m.selectedTable, where, int64(n))
+		})
+	}
+
+	var cmd tea.Cmd
+	m.deleteConfirm, cmd = m.deleteConfirm.Update(msg)
+	return m, cmd
+}
+
+func (m Model) viewDelete() string {
+	s := m.theme.Title.Render("Delete from "+m.selectedTable) + "\n\n"
+	s += "DELETE FROM " + m.selectedTable + " WHERE " + m.deleteWhere + "\n\n"
+
+	page := m.deletePreview
+	if page.TotalRows == 0 {
+		s += "No rows match.\n"
+	} else {
+		grid := table.RenderStyled(page.Columns, page.Rows, m.theme, table.Highlight{Row: -1, Col: -1})
+		if m.width > 0 {
+			grid = table.ApplyHorizontalScroll(grid, 0, m.width)
+		}
+		s += grid
+		if len(page.Rows) < page.TotalRows {
+			s += fmt.Sprintf("... and %d more\n", page.TotalRows-len(page.Rows))
+		}
+		s += "\n" + m.theme.Error.Render(fmt.Sprintf("%d row(s) will be deleted.", page.TotalRows)) + "\n"
+		s += m.inputBox("Confirm", m.deleteConfirm.View())
+	}
+
+	s += "\n" + m.theme.Status.Render(m.status) + "\n"
+	return s
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/export"
+)
+
+// ----- Messages -----
+
+type updateResultMsg struct {
+	column string
+	value  any
+	images []db.RowImage // rows before the update, for undo
+	err    error
+}
+
+// pendingUpdate is an UPDATE waiting for confirmation.
+type pendingUpdate struct {
+	column string
+	value  any // string, or nil for NULL
+	key    db.RowKey
+	args   string // bound values as shown to the user
+}
+
+// ----- Commands -----
+
+func updateRowCmd(client db.DB, tableName string, u pendingUpdate) tea.Cmd {
+	return func() tea.Msg {
+		images, err := client.UpdateRow(context.Background(), tableName, u.key, u.column, u.value)
+		return updateResultMsg{column: u.column, value: u.value, images: images, err: err}
+	}
+}
+
+// ----- Row keys -----
+
+// rowKey returns the key of the row under the cursor, or an error saying
+// why the row can't be addressed.
+func (m Model) rowKey() (db.RowKey, error) {
+	if m.rowCursor >= len(m.rows) {
+		return db.RowKey{}, fmt.Errorf("no row selected")
+	}
+	return m.keyOf(m.rows[m.rowCursor])
+}
+
+// keyOf returns the key of a row fetched with the current columns.
+func (m Model) keyOf(row []db.Value) (db.RowKey, error) {
+	if len(m.keyColumns) == 0 {
+		return db.RowKey{}, fmt.Errorf("table %s has no primary or unique key, so rows can't be addressed", m.selectedTable)
+	}
+
+	key := db.RowKey{Columns: m.keyColumns}
+	var missing []string
+	for _, name := range m.keyColumns {
+		idx := -1
+		for i, c := range m.columns {
+			if c.Name == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 || idx >= len(row) {
+			missing = append(missing, name)
+			continue
+		}
+		key.Values = append(key.Values, row[idx].Raw)
+	}
+	if len(missing) > 0 {
+		return db.RowKey{}, fmt.Errorf("show the key column(s) %s to edit rows ('c' to choose columns)", strings.Join(missing, ", "))
+	}
+	return key, nil
+}
+
+// keyArgs describes the key values of the cursor row for the SQL preview.
+func (m Model) keyArgs(first int) string {
+	var args []string
+	row := m.rows[m.rowCursor]
+	for i, name := range m.keyColumns {
+		for j, c := range m.columns {
+			if c.Name == name && j < len(row) {
+				args = append(args, fmt.Sprintf("$%d = %s", first+i, export.Literal(c, row[j])))
+			}
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// ----- Cell editor -----
+
+// inputText is text Postgres parses back to the same value; "" for NULL.
+func inputText(v db.Value) string {
+	if v.Null {
+		return ""
+	}
+	switch raw := v.Raw.(type) {
+	case string:
+		return raw
+	case []byte:
+		return `\x` + hex.EncodeToString(raw)
+	}
+	return v.Display
+}
+
+func (m Model) openEditor() (tea.Model, tea.Cmd) {
+	value, ok := m.cellAt()
+	if !ok {
+		return m, nil
+	}
+	if _, err := m.rowKey(); err != nil {
+		m.status = "Cannot edit: " + err.Error()
+		return m, nil
+	}
+
+	text := inputText(value)
+	m.editNull = value.Null
+
+	m.editingCell = true
+	m.editInput.SetValue(text)
+	m.editInput.CursorEnd()
+	m.editInput.Focus()
+	m.status = m.editStatus()
+	return m, nil
+}
+
+func (m Model) editStatus() string {
+	s := fmt.Sprintf("Editing %s (%s). Enter to review the UPDATE, ctrl+n to toggle NULL, Esc to cancel.", m.columns[m.colCursor].Name, m.columns[m.colCursor].TypeName)
+	if m.editNull {
+		s += " Value: NULL."
+	}
+	return s
+}
+
+func (m Model) updateEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// confirmation of the generated statement
+	if m.pendingUpdate != nil {
+		switch msg.String() {
+		case "y", "enter":
+			u := *m.pendingUpdate
+			m.pendingUpdate = nil
+			m.editingCell = false
+			m.loading = true
+			m.status = "Updating..."
+			return m.guardWrite("update "+u.column+" of one row", func() tea.Cmd {
+				return updateRowCmd(m.dbClient, m.selectedTable, u)
+			})
+		case "n", "esc", "ctrl+c":
+			m.pendingUpdate = nil
+			m.status = m.editStatus()
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.editingCell = false
+		m.status = "Edit cancelled."
+		return m, nil
+
+	case "ctrl+n":
+		m.editNull = !m.editNull
+		m.status = m.editStatus()
+		return m, nil
+
+	case "enter":
+		key, err := m.rowKey()
+		if err != nil {
+			m.status = "Cannot edit: " + err.Error()
+			return m, nil
+		}
+		col := m.columns[m.colCursor]
+
+		u := pendingUpdate{column: col.Name, key: key}
+		if m.editNull {
+			u.args = "$1 = NULL"
+		} else {
+			u.value = m.editInput.Value()
+			u.args = "$1 = " + export.QuoteLiteral(m.editInput.Value())
+		}
+		u.args += ", " + m.keyArgs(2)
+
+		m.pendingUpdate = &u
+		m.status = "Run " + db.UpdateSQL(m.selectedTable, key, col.Name) + " with " + u.args + "? (y/n)"
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.editInput, cmd = m.editInput.Update(msg)
+	if m.editInput.Value() != "" {
+		m.editNull = false
+	}
+	return m, cmd
+}
@@ -0,0 +1,258 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/export"
+)
+
+// exportBatch is how many rows are fetched per query when exporting the
+// full filtered result.
+const exportBatch = 1000
+
+// exportScope selects which rows an export writes.
+type exportScope int
+
+const (
+	scopePage   exportScope = iota // rows on screen
+	scopeAll                       // full filtered result
+	scopeMarked                    // rows marked with Space
+)
+
+func (s exportScope) String() string {
+	switch s {
+	case scopeAll:
+		return "full filtered result"
+	case scopeMarked:
+		return "marked rows"
+	}
+	return "current page"
+}
+
+// ----- Messages -----
+
+// exportProgressMsg reports rows written so far; progress delivers the
+// next message from the running export.
+type exportProgressMsg struct {
+	written  int
+	total    int
+	progress <-chan tea.Msg
+}
+
+type exportDoneMsg struct {
+	path    string
+	written int
+	err     error
+}
+
+// ----- Commands -----
+
+// exportRowsCmd writes rows already in memory: the page or the marked rows.
+func exportRowsCmd(path string, format export.Format, opts export.Options, columns []db.Column, rows [][]db.Value) tea.Cmd {
+	return func() tea.Msg {
+		err := writeExport(path, format, opts, func(wr export.Writer) error {
+			return export.WriteAll(wr, columns, rows)
+		})
+		return exportDoneMsg{path: path, written: len(rows), err: err}
+	}
+}
+
+// exportAllCmd streams the full filtered result from one snapshot in
+// batches, reporting progress after each one.
+func exportAllCmd(client db.DB, tableName string, opts db.QueryOptions, path string, format export.Format, exportOpts export.Options) tea.Cmd {
+	progress := make(chan tea.Msg)
+
+	go func() {
+		defer close(progress)
+
+		written := 0
+		err := writeExport(path, format, exportOpts, func(wr export.Writer) error {
+			opts.Limit = exportBatch
+			err := client.StreamRows(context.Background(), tableName, opts, func(page db.RowPage) error {
+				if page.Offset == 0 {
+					if err := wr.Begin(page.Columns); err != nil {
+						return err
+					}
+				}
+				for _, row := range page.Rows {
+					if err := wr.Row(row); err != nil {
+						return err
+					}
+				}
+				written += len(page.Rows)
+				progress <- exportProgressMsg{written: written, total: page.TotalRows, progress: progress}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			return wr.End()
+		})
+		progress <- exportDoneMsg{path: path, written: written, err: err}
+	}()
+
+	return waitForProgress(progress)
+}
+
+// waitForProgress delivers the next message of a background job (export or
+// import); progress messages carry the channel so the wait can be re-issued.
+func waitForProgress(progress <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-progress
+	}
+}
+
+// writeExport creates the file, hands a writer for the format to fill, and
+// removes the file again if filling it fails.
+func writeExport(path string, format export.Format, opts export.Options, fill func(export.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	wr, err := export.New(format, f, opts)
+	if err == nil {
+		err = fill(wr)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// expandPath resolves a leading ~ to the home directory.
+func expandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// ----- Export prompt -----
+
+func (m Model) openExport() (tea.Model, tea.Cmd) {
+	if len(m.columns) == 0 {
+		return m, nil
+	}
+	m.exporting = true
+	m.editingFilter = false
+	m.editingDelete = false
+	if m.exportScope == scopeMarked && len(m.marked) == 0 {
+		m.exportScope = scopePage
+	}
+	m.exportInput.SetValue(m.selectedTable + m.exportFormat.Ext())
+	m.exportInput.CursorEnd()
+	m.exportInput.Focus()
+	m.status = m.exportPromptStatus()
+	return m, nil
+}
+
+func (m Model) exportPromptStatus() string {
+	s := fmt.Sprintf("Export %s as %s. Tab to change format, ctrl+a to change rows, Enter to write, Esc to cancel.", m.exportScope, m.exportFormat)
+	if m.exportFormat == export.SQL {
+		s += fmt.Sprintf(" ctrl+r multi-row INSERT (%s), ctrl+o ON CONFLICT DO NOTHING (%s).", onOff(m.exportMultiRow), onOff(m.exportOnConflict))
+	}
+	return s
+}
+
+// exportOptions carries the prompt toggles to the writer.
+func (m Model) exportOptions() export.Options {
+	return export.Options{
+		Schema:              "public",
+		Table:               m.selectedTable,
+		TableColumns:        m.allColumns,
+		MultiRow:            m.exportMultiRow,
+		OnConflictDoNothing: m.exportOnConflict,
+	}
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func (m Model) updateExportKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.exporting = false
+		m.status = "Export cancelled."
+		return m, nil
+
+	case "tab":
+		next := export.Formats[0]
+		for i, f := range export.Formats {
+			if f == m.exportFormat {
+				next = export.Formats[(i+1)%len(export.Formats)]
+			}
+		}
+		name := m.exportInput.Value()
+		if _, ok := export.FormatForPath(name); ok {
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		m.exportFormat = next
+		m.exportInput.SetValue(name + next.Ext())
+		m.exportInput.CursorEnd()
+		m.status = m.exportPromptStatus()
+		return m, nil
+
+	case "ctrl+a":
+		m.exportScope = (m.exportScope + 1) % 3
+		if m.exportScope == scopeMarked && len(m.marked) == 0 {
+			m.exportScope = scopePage
+		}
+		m.status = m.exportPromptStatus()
+		return m, nil
+
+	case "ctrl+r":
+		m.exportMultiRow = !m.exportMultiRow
+		m.status = m.exportPromptStatus()
+		return m, nil
+
+	case "ctrl+o":
+		m.exportOnConflict = !m.exportOnConflict
+		m.status = m.exportPromptStatus()
+		return m, nil
+
+	case "enter":
+		path := expandPath(strings.TrimSpace(m.exportInput.Value()))
+		if path == "" {
+			m.status = "File name cannot be empty."
+			return m, nil
+		}
+		if f, ok := export.FormatForPath(path); ok {
+			m.exportFormat = f
+		}
+		m.exporting = false
+		m.exportRunning = true
+
+		switch m.exportScope {
+		case scopeAll:
+			m.status = "Exporting to " + path + "..."
+			return m, exportAllCmd(m.dbClient, m.selectedTable, m.queryOptions(0), path, m.exportFormat, m.exportOptions())
+		case scopeMarked:
+			if len(m.marked) > 0 {
+				m.status = "Exporting marked rows to " + path + "..."
+				return m, exportRowsCmd(path, m.exportFormat, m.exportOptions(), m.columns, m.markedRows())
+			}
+		}
+		m.status = "Exporting page to " + path + "..."
+		return m, exportRowsCmd(path, m.exportFormat, m.exportOptions(), m.columns, m.rows)
+	}
+
+	var cmd tea.Cmd
+	m.exportInput, cmd = m.exportInput.Update(msg)
+	return m, cmd
+}
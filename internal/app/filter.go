@@ -0,0 +1,469 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/export"
+	"github.com/hrutik5321/dbls/internal/ui/table"
+	"github.com/mattn/go-runewidth"
+)
+
+type filterCheckMsg struct {
+	filter string
+	where  db.Expr
+	chips  []filterChip // the chips where was built from
+	err    error
+}
+
+func checkFilterCmd(client db.DB, tableName string, opts db.QueryOptions, filter string, where db.Expr, chips []filterChip) tea.Cmd {
+	return func() tea.Msg {
+		err := client.CheckFilter(context.Background(), tableName, opts)
+		return filterCheckMsg{filter: filter, where: where, chips: chips, err: err}
+	}
+}
+
+// ----- Structured filter -----
+
+// filterChip is one condition of the filter builder.
+type filterChip struct {
+	or     bool // joined to the chip before with OR instead of AND
+	column int  // index into allColumns
+	op     int  // index into db.Ops
+	value  textinput.Model
+}
+
+// chip fields, in Tab order
+const (
+	chipColumn = iota
+	chipOp
+	chipValue
+)
+
+func (m Model) newChip() filterChip {
+	in := textinput.New()
+	in.Prompt = ""
+	in.Placeholder = "value"
+
+	// start on the column under the cell cursor
+	column := 0
+	if m.colCursor < len(m.columns) {
+		for i, c := range m.allColumns {
+			if c.Name == m.columns[m.colCursor].Name {
+				column = i
+			}
+		}
+	}
+	return filterChip{column: column, value: in}
+}
+
+func (m Model) chipCond(c filterChip) db.Cond {
+	return db.Cond{Column: m.allColumns[c.column].Name, Op: db.Ops[c.op], Value: c.value.Value()}
+}
+
+// cloneChips copies chips with value inputs of their own, so editing the
+// copy leaves the original alone.
+func cloneChips(chips []filterChip) []filterChip {
+	if chips == nil {
+		return nil
+	}
+	out := make([]filterChip, len(chips))
+	for i, c := range chips {
+		c.value.SetValue(c.value.Value())
+		out[i] = c
+	}
+	return out
+}
+
+// chipsExpr builds the filter tree: chips joined by AND form groups, and
+// the groups are joined by OR, so AND binds tighter as in SQL. It returns
+// nil for no chips.
+func (m Model) chipsExpr() db.Expr {
+	if len(m.chips) == 0 {
+		return nil
+	}
+
+	var (
+		either = db.Group{Or: true}
+		each   db.Group
+	)
+	flush := func() {
+		if len(each.Items) == 1 {
+			either.Items = append(either.Items, each.Items[0])
+		} else {
+			either.Items = append(either.Items, each)
+		}
+		each = db.Group{}
+	}
+	for i, c := range m.chips {
+		if i > 0 && c.or {
+			flush()
+		}
+		each.Items = append(each.Items, m.chipCond(c))
+	}
+	flush()
+
+	if len(either.Items) == 1 {
+		return either.Items[0]
+	}
+	return either
+}
+
+// filterText is the active filter as shown to the user, "" for none.
+func (m Model) filterText() string {
+	if m.filterWhere != nil {
+		return m.filterWhere.String()
+	}
+	return m.filter
+}
+
+func (m Model) openFilterBuilder() (tea.Model, tea.Cmd) {
+	if len(m.allColumns) == 0 {
+		return m, nil
+	}
+	m.editingFilter = false
+	m.filterErr = nil
+	if len(m.chips) == 0 {
+		m.chips = []filterChip{m.newChip()}
+	}
+	m.chipCursor = 0
+	m.chipField = chipColumn
+	m.focusChip()
+	m.mode = modeFilter
+	m.status = "←/→ choose the column and operator, Tab moves between them. ctrl+a adds a condition, ctrl+o switches AND/OR, ctrl+t for raw SQL."
+	return m, nil
+}
+
+func (m *Model) focusChip() {
+	for i := range m.chips {
+		if i == m.chipCursor && m.chipField == chipValue {
+			m.chips[i].value.Focus()
+		} else {
+			m.chips[i].value.Blur()
+		}
+	}
+}
+
+func (m Model) updateFilterBuilderKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+	case "esc":
+		m.mode = modeRows
+		m.checkingFilter = false
+		m.filterErr = nil
+		m.chips = cloneChips(m.appliedChips)
+		m.status = "Filter unchanged."
+		return m, nil
+
+	case "enter":
+		return m.applyFilter("", m.chipsExpr())
+
+	case "ctrl+t":
+		// same conditions as SQL, to edit further by hand
+		m.mode = modeRows
+		m.editingFilter = true
+		m.filterErr = nil
+		if e := m.chipsExpr(); e != nil {
+			m.filterInput.SetValue(e.String())
+		}
+		m.filterInput.CursorEnd()
+		m.filterInput.Focus()
+		m.status = "Enter SQL WHERE clause (without 'WHERE'). Enter to apply, Esc to cancel, ctrl+t for the builder."
+		return m, nil
+
+	case "ctrl+a":
+		c := m.newChip()
+		i := m.chipCursor + 1
+		if len(m.chips) == 0 {
+			i = 0
+		}
+		m.chips = append(m.chips[:i], append([]filterChip{c}, m.chips[i:]...)...)
+		m.chipCursor = i
+		m.chipField = chipColumn
+	case "ctrl+x":
+		if len(m.chips) > 0 {
+			m.chips = append(m.chips[:m.chipCursor], m.chips[m.chipCursor+1:]...)
+			if m.chipCursor >= len(m.chips) && m.chipCursor > 0 {
+				m.chipCursor--
+			}
+		}
+	case "ctrl+o":
+		if m.chipCursor > 0 {
+			m.chips[m.chipCursor].or = !m.chips[m.chipCursor].or
+		}
+
+	case "up":
+		if m.chipCursor > 0 {
+			m.chipCursor--
+		}
+	case "down":
+		if m.chipCursor < len(m.chips)-1 {
+			m.chipCursor++
+		}
+	case "tab":
+		m.chipField = (m.chipField + 1) % 3
+	case "shift+tab":
+		m.chipField = (m.chipField + 2) % 3
+
+	default:
+		if len(m.chips) == 0 {
+			return m, nil
+		}
+		c := &m.chips[m.chipCursor]
+		switch m.chipField {
+		case chipColumn:
+			c.column = cycle(c.column, len(m.allColumns), msg.String())
+		case chipOp:
+			c.op = cycle(c.op, len(db.Ops), msg.String())
+		case chipValue:
+			var cmd tea.Cmd
+			c.value, cmd = c.value.Update(msg)
+			return m, cmd
+		}
+	}
+
+	m.focusChip()
+	return m, nil
+}
+
+// cycle moves i through n choices on ←/→, wrapping around.
+func cycle(i, n int, key string) int {
+	switch key {
+	case "left":
+		return (i + n - 1) % n
+	case "right":
+		return (i + 1) % n
+	}
+	return i
+}
+
+func (m Model) viewFilterBuilder() string {
+	s := m.theme.Title.Render("Filter "+m.selectedTable) + "\n\n"
+
+	if len(m.chips) == 0 {
+		s += "  (no conditions: every row matches; ctrl+a to add one)\n"
+	}
+
+	nameWidth := 0
+	for _, c := range m.allColumns {
+		nameWidth = max(nameWidth, table.Width(c.Name))
+	}
+
+	for i, c := range m.chips {
+		cursor := "  "
+		if i == m.chipCursor {
+			cursor = "> "
+		}
+		join := "    "
+		switch {
+		case i > 0 && c.or:
+			join = "OR  "
+		case i > 0:
+			join = "AND "
+		}
+
+		name := m.allColumns[c.column].Name
+		name += strings.Repeat(" ", nameWidth-table.Width(name))
+		op := fmt.Sprintf("%-11s", db.Ops[c.op])
+		if i == m.chipCursor {
+			switch m.chipField {
+			case chipColumn:
+				name = m.theme.Selected.Render(name)
+			case chipOp:
+				op = m.theme.Selected.Render(op)
+			}
+		}
+
+		value := ""
+		switch {
+		case db.Ops[c.op].Unary():
+		case i == m.chipCursor && m.chipField == chipValue:
+			value = c.value.View()
+		default:
+			value = db.QuoteLiteral(c.value.Value())
+		}
+
+		s += fmt.Sprintf("%s%s%s  %s  %s\n", cursor, m.theme.Help.Render(join), name, op, value)
+	}
+
+	if e := m.chipsExpr(); e != nil {
+		s += "\nWHERE " + e.String() + "\n"
+		s += m.theme.Help.Render("  -- values are sent as bound parameters") + "\n"
+	}
+	s += m.viewFilterErr()
+
+	s += "\n" + m.theme.Status.Render(m.status) + "\n"
+	s += "\n" + m.theme.Help.Render("↑/↓ pick a condition, Tab/Shift+Tab pick column, operator or value, ←/→ change it. ctrl+x removes a condition. Enter applies, Esc cancels.") + "\n"
+	return s
+}
+
+// ----- Quick filter from the cell under the cursor -----
+
+// quickFilter narrows the rows to those whose column compares with the
+// cell under the cursor as op (OpLike matches the cell as a prefix). On a
+// NULL cell, OpEq and OpNe become IS NULL and IS NOT NULL.
+func (m Model) quickFilter(op db.Op) (tea.Model, tea.Cmd) {
+	value, ok := m.cellAt()
+	if !ok {
+		return m, nil
+	}
+	col := m.columns[m.colCursor]
+	if m.jsonPath != nil && col.Name == m.pathColumn {
+		m.status = "Clear the JSON path ('.' then Esc) to filter on " + col.Name + "."
+		return m, nil
+	}
+
+	cond := db.Cond{Column: col.Name, Op: op, Value: inputText(value)}
+	if value.Null {
+		switch op {
+		case db.OpEq:
+			cond.Op = db.OpIsNull
+		case db.OpNe:
+			cond.Op = db.OpNotNull
+		default:
+			m.status = "A NULL cell can only be filtered with '=' or '!'."
+			return m, nil
+		}
+	}
+	if op == db.OpLike {
+		cond.Value = db.EscapeLike(cond.Value) + "%"
+	}
+
+	if m.filter != "" {
+		// raw SQL stays raw: append the condition with the value as a
+		// literal of the column type
+		sql := db.QuoteIdent(col.Name) + " " + cond.Op.String()
+		switch {
+		case cond.Op.Unary():
+		case op == db.OpLike:
+			sql = db.QuoteIdent(col.Name) + "::text LIKE " + db.QuoteLiteral(cond.Value)
+		default:
+			sql += " " + export.Literal(col, value)
+		}
+		// keep the filter together whatever it is made of, so an OR in
+		// it can't take the new condition along
+		return m.applyFilter("("+m.filter+") AND "+sql, nil)
+	}
+
+	idx := -1
+	for i, c := range m.allColumns {
+		if c.Name == col.Name {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return m, nil
+	}
+	opIdx := 0
+	for i, o := range db.Ops {
+		if o == cond.Op {
+			opIdx = i
+		}
+	}
+	newChip := func() filterChip {
+		chip := m.newChip()
+		chip.column, chip.op = idx, opIdx
+		chip.value.SetValue(cond.Value)
+		return chip
+	}
+
+	// chips are AND groups joined by OR, so the condition goes at the end
+	// of every group: (a OR b) AND c is (a AND c) OR (b AND c)
+	var chips []filterChip
+	for i, c := range cloneChips(m.appliedChips) {
+		if i > 0 && c.or {
+			chips = append(chips, newChip())
+		}
+		chips = append(chips, c)
+	}
+	m.chips = append(chips, newChip())
+	return m.applyFilter("", m.chipsExpr())
+}
+
+// ----- Checking a filter before it runs -----
+
+// applyFilter has the database check a raw filter or a built one before it
+// replaces the active filter, so a typo leaves the rows on screen.
+func (m Model) applyFilter(filter string, where db.Expr) (tea.Model, tea.Cmd) {
+	next := m
+	next.filter, next.filterWhere = filter, where
+	m.checkingFilter = true
+	m.loading = true
+	m.status = "Checking filter..."
+	var chips []filterChip
+	if where != nil {
+		chips = cloneChips(m.chips)
+	}
+	return m, checkFilterCmd(m.dbClient, m.selectedTable, next.queryOptions(0), filter, where, chips)
+}
+
+func (m Model) filterChecked(msg filterCheckMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if !m.checkingFilter {
+		// cancelled while the database was looking at it
+		return m, nil
+	}
+	m.checkingFilter = false
+
+	if msg.err != nil {
+		// show the rejected filter in its box to fix it
+		switch {
+		case msg.where != nil && m.mode != modeFilter:
+			next, _ := m.openFilterBuilder()
+			m = next.(Model)
+		case msg.where == nil && !m.editingFilter:
+			m.editingFilter = true
+			m.filterInput.SetValue(msg.filter)
+			m.filterInput.CursorEnd()
+			m.filterInput.Focus()
+		}
+		m.filterErr = msg.err
+		m.filterRejected = msg.filter
+		var fe *db.FilterError
+		if errors.As(msg.err, &fe) && fe.Position > 0 && m.filterInput.Value() == msg.filter {
+			m.filterInput.SetCursor(fe.Position - 1)
+		}
+		m.status = "The database rejected the filter. Fix it and press Enter again."
+		return m, nil
+	}
+
+	m.filter, m.filterWhere = msg.filter, msg.where
+	// the builder opens on the filter in effect, none after a raw one
+	m.chips, m.appliedChips = msg.chips, cloneChips(msg.chips)
+	m.filterErr = nil
+	m.editingFilter = false
+	m.mode = modeRows
+	m.rowCursor = 0
+	m.clearMarks()
+	m.loading = true
+	m.status = "Applying filter..."
+	return m, fetchRowsCmd(m.dbClient, m.selectedTable, m.queryOptions(0))
+}
+
+// viewFilterErr shows why the filter was rejected under its box, with the
+// character the database pointed at highlighted.
+func (m Model) viewFilterErr() string {
+	if m.filterErr == nil {
+		return ""
+	}
+	s := ""
+	var fe *db.FilterError
+	if errors.As(m.filterErr, &fe) && fe.Position > 0 {
+		text := []rune(m.filterRejected)
+		at := min(fe.Position-1, len(text))
+		before, mark, after := string(text[:at]), " ", ""
+		if at < len(text) {
+			mark, after = string(text[at]), string(text[at+1:])
+		}
+		indent := "  " + m.filterInput.Prompt
+		s += indent + before + m.theme.Error.Render(mark) + after + "\n"
+		s += strings.Repeat(" ", runewidth.StringWidth(indent+before)) + m.theme.Error.Render("^") + "\n"
+	}
+	return s + "  " + m.theme.Error.Render(m.filterErr.Error()) + "\n"
+}
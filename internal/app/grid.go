@@ -0,0 +1,150 @@
+package app
+
+import (
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/ui/table"
+)
+
+// ----- Frozen columns + column scrolling -----
+
+// frozen reports whether a column stays on the left while scrolling:
+// primary-key columns unless unpinned, plus any column pinned with 'f'.
+func (m Model) frozen(col db.Column) bool {
+	if pinned, ok := m.pins[col.Name]; ok {
+		return pinned
+	}
+	return col.PrimaryKey
+}
+
+// displayOrder returns column indexes in drawing order (frozen columns
+// first) and how many of them are frozen.
+func (m Model) displayOrder(columns []db.Column) ([]int, int) {
+	order := make([]int, 0, len(columns))
+	for i, c := range columns {
+		if m.frozen(c) {
+			order = append(order, i)
+		}
+	}
+	nFrozen := len(order)
+	for i, c := range columns {
+		if !m.frozen(c) {
+			order = append(order, i)
+		}
+	}
+	return order, nFrozen
+}
+
+func (m Model) frozenNames() []string {
+	var names []string
+	for _, c := range m.columns {
+		if m.frozen(c) {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// visibleGrid returns the columns and rows drawn at the current scroll
+// position: frozen columns, then scrollable ones from colOffset on. The
+// last value is the position of the cursor column among them, or -1.
+func (m Model) visibleGrid() ([]db.Column, [][]db.Value, int) {
+	columns, rows := m.columns, m.rows
+	if m.jsonPath != nil {
+		columns, rows = m.withPathColumn()
+	}
+
+	order, nFrozen := m.displayOrder(columns)
+	offset := min(m.colOffset, max(len(order)-nFrozen-1, 0))
+	pick := append(append([]int{}, order[:nFrozen]...), order[nFrozen+offset:]...)
+
+	cursor := -1
+	visCols := make([]db.Column, len(pick))
+	for i, idx := range pick {
+		visCols[i] = columns[idx]
+		if idx == m.colCursor {
+			cursor = i
+		}
+	}
+	visRows := make([][]db.Value, len(rows))
+	for r, row := range rows {
+		visRows[r] = make([]db.Value, len(pick))
+		for i, idx := range pick {
+			if idx < len(row) {
+				visRows[r][i] = row[idx]
+			}
+		}
+	}
+	return visCols, visRows, cursor
+}
+
+// scrollColumns moves the scroll position by delta scrollable columns.
+func (m *Model) scrollColumns(delta int) {
+	_, nFrozen := m.displayOrder(m.columns)
+	last := max(len(m.columns)-nFrozen-1, 0)
+	m.colOffset = min(max(m.colOffset+delta, 0), last)
+}
+
+// moveColCursor moves the cell cursor through the columns in drawing order
+// and scrolls so the cursor column stays visible.
+func (m *Model) moveColCursor(delta int) {
+	order, nFrozen := m.displayOrder(m.columns)
+	pos := -1
+	for i, idx := range order {
+		if idx == m.colCursor {
+			pos = i
+		}
+	}
+	pos += delta
+	if pos < 0 || pos >= len(order) {
+		return
+	}
+	m.colCursor = order[pos]
+
+	if pos < nFrozen {
+		return
+	}
+	scrollPos := pos - nFrozen
+	if scrollPos < m.colOffset {
+		m.colOffset = scrollPos
+		return
+	}
+	if m.width <= 0 {
+		return
+	}
+
+	// scroll right until the cursor column fits on screen
+	widths := table.ColumnWidths(m.columns, m.rows)
+	fixed := 2 + 1 // gutter + left border
+	for _, idx := range order[:nFrozen] {
+		fixed += widths[idx]
+	}
+	for m.colOffset < scrollPos {
+		used := fixed
+		for _, idx := range order[nFrozen+m.colOffset : pos+1] {
+			used += widths[idx]
+		}
+		if used <= m.width {
+			break
+		}
+		m.colOffset++
+	}
+}
+
+// togglePin freezes or unfreezes the column under the cursor.
+func (m *Model) togglePin() {
+	if m.colCursor >= len(m.columns) {
+		return
+	}
+	col := m.columns[m.colCursor]
+	if m.pins == nil {
+		m.pins = make(map[string]bool)
+	}
+	m.pins[col.Name] = !m.frozen(col)
+	m.scrollColumns(0)
+
+	if m.pins[col.Name] {
+		m.status = "Froze column " + col.Name + "."
+	} else {
+		m.status = "Unfroze column " + col.Name + "."
+	}
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ----- Read-only and production sessions -----
+
+// allowWrite reports whether a write action may start; in a read-only
+// session it says why not in the status line.
+func (m *Model) allowWrite() bool {
+	if m.opts.ReadOnly {
+		m.status = "Read-only session: writes are disabled."
+		return false
+	}
+	return true
+}
+
+// guardedWrite is a write waiting for the production confirmation. run
+// builds the command only once confirmed, since some commands start work
+// as soon as they are created.
+type guardedWrite struct {
+	what string
+	run  func() tea.Cmd
+}
+
+// guardWrite runs a write, asking first for the database name when the
+// profile is marked as production.
+func (m Model) guardWrite(what string, run func() tea.Cmd) (tea.Model, tea.Cmd) {
+	if !m.opts.Profile.Production {
+		return m, run()
+	}
+	m.guard = &guardedWrite{what: what, run: run}
+	m.guardInput.SetValue("")
+	m.guardInput.Focus()
+	m.status = "PRODUCTION: " + what + ". Type the database name " + m.dbInput.Value() + " and press Enter to go ahead, Esc to cancel."
+	return m, nil
+}
+
+func (m Model) updateGuardKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.guard = nil
+		m.loading = false
+		m.importRunning = false
+		m.status = "Write cancelled."
+		return m, nil
+
+	case "enter":
+		if strings.TrimSpace(m.guardInput.Value()) != m.dbInput.Value() {
+			m.status = "That is not the database name. Type " + m.dbInput.Value() + " to go ahead, Esc to cancel."
+			return m, nil
+		}
+		run := m.guard.run
+		m.guard = nil
+		return m, run()
+	}
+
+	var cmd tea.Cmd
+	m.guardInput, cmd = m.guardInput.Update(msg)
+	return m, cmd
+}
+
+// banner marks production and read-only sessions, and an audit log that
+// could not be written, at the top of every screen after connecting.
+func (m Model) banner() string {
+	var parts []string
+	if m.opts.Profile.Production {
+		name := m.opts.ProfileName
+		if name == "" {
+			name = m.dbInput.Value()
+		}
+		parts = append(parts, m.theme.Banner.Render("PRODUCTION: "+name))
+	}
+	if m.opts.ReadOnly {
+		parts = append(parts, m.theme.Help.Render("[read-only]"))
+	}
+	if m.auditErr != nil {
+		parts = append(parts, m.theme.Error.Render("Audit log not written: "+m.auditErr.Error()))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, " ") + "\n\n"
+}
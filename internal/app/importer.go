@@ -0,0 +1,237 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/csvimport"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/ui/table"
+)
+
+// importPreviewRows is how many CSV rows the mapping screen shows.
+const importPreviewRows = 5
+
+// ----- Messages -----
+
+type importPreviewMsg struct {
+	columns []db.Column
+	header  []string
+	rows    [][]csvimport.Field
+	err     error
+}
+
+type importProgressMsg struct {
+	rows     int
+	progress <-chan tea.Msg
+}
+
+type importDoneMsg struct {
+	rows int64
+	line int // CSV line that failed, 0 if unknown
+	err  error
+}
+
+// ----- Commands -----
+
+func importPreviewCmd(client db.DB, tableName, path string) tea.Cmd {
+	return func() tea.Msg {
+		columns, err := client.ListColumns(context.Background(), tableName)
+		if err != nil {
+			return importPreviewMsg{err: err}
+		}
+		header, rows, err := csvimport.Preview(path, importPreviewRows)
+		return importPreviewMsg{columns: columns, header: header, rows: rows, err: err}
+	}
+}
+
+// importCmd loads the file with COPY, reporting progress every 1000 rows.
+func importCmd(client db.DB, tableName, path string, columns []string, fields []int) tea.Cmd {
+	progress := make(chan tea.Msg)
+
+	go func() {
+		defer close(progress)
+
+		src, err := csvimport.Open(path, fields, func(rows int) {
+			progress <- importProgressMsg{rows: rows, progress: progress}
+		})
+		if err != nil {
+			progress <- importDoneMsg{err: err}
+			return
+		}
+		defer src.Close()
+
+		n, err := client.CopyRows(context.Background(), tableName, columns, src)
+		done := importDoneMsg{rows: n, err: err}
+		var (
+			copyErr  *db.CopyError
+			parseErr *csvimport.ParseError
+		)
+		switch {
+		case err == nil:
+		case errors.As(err, &parseErr):
+			// the file itself is malformed; the error names its line
+		case errors.As(err, &copyErr):
+			done.line = src.Line(copyErr.Row)
+		default:
+			done.line = src.Line(0)
+		}
+		progress <- done
+	}()
+
+	return waitForProgress(progress)
+}
+
+// ----- File prompt (tables screen) -----
+
+func (m Model) openImport() (tea.Model, tea.Cmd) {
+	if len(m.tableNames) == 0 {
+		return m, nil
+	}
+	m.importing = true
+	m.importInput.SetValue(m.tableNames[m.tableCursor] + ".csv")
+	m.importInput.CursorEnd()
+	m.importInput.Focus()
+	m.status = fmt.Sprintf("CSV file to import into %s. Enter to preview, Esc to cancel.", m.tableNames[m.tableCursor])
+	return m, nil
+}
+
+func (m Model) updateImportPromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.importing = false
+		m.status = "Import cancelled."
+		return m, nil
+
+	case "enter":
+		path := expandPath(strings.TrimSpace(m.importInput.Value()))
+		if path == "" {
+			m.status = "File name cannot be empty."
+			return m, nil
+		}
+		m.importing = false
+		m.importTable = m.tableNames[m.tableCursor]
+		m.importPath = path
+		m.loading = true
+		m.status = "Reading " + path + "..."
+		return m, importPreviewCmd(m.dbClient, m.importTable, path)
+	}
+
+	var cmd tea.Cmd
+	m.importInput, cmd = m.importInput.Update(msg)
+	return m, cmd
+}
+
+// ----- Mapping screen -----
+
+func (m Model) updateImportKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.importRunning {
+		if msg.String() == "ctrl+c" {
+			return m.quit()
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+	case "esc", "b":
+		m.mode = modeTables
+		m.status = "Import cancelled."
+	case "up", "k":
+		if m.importCursor > 0 {
+			m.importCursor--
+		}
+	case "down", "j":
+		if m.importCursor < len(m.importColumns)-1 {
+			m.importCursor++
+		}
+	// cycle the CSV field for the column: skip, then every header field
+	case "right", "l":
+		m.importMapping[m.importCursor]++
+		if m.importMapping[m.importCursor] >= len(m.importHeader) {
+			m.importMapping[m.importCursor] = -1
+		}
+	case "left", "h":
+		m.importMapping[m.importCursor]--
+		if m.importMapping[m.importCursor] < -1 {
+			m.importMapping[m.importCursor] = len(m.importHeader) - 1
+		}
+	case "s":
+		m.importMapping[m.importCursor] = -1
+
+	case "enter":
+		var columns []string
+		var fields []int
+		for i, c := range m.importColumns {
+			if idx := m.importMapping[i]; idx >= 0 {
+				columns = append(columns, c.Name)
+				fields = append(fields, idx)
+			}
+		}
+		if len(columns) == 0 {
+			m.status = "Map at least one column before importing."
+			return m, nil
+		}
+		m.importRunning = true
+		m.status = fmt.Sprintf("Importing %s into %s...", m.importPath, m.importTable)
+		return m.guardWrite("import "+m.importPath+" into "+m.importTable, func() tea.Cmd {
+			return importCmd(m.dbClient, m.importTable, m.importPath, columns, fields)
+		})
+	}
+	return m, nil
+}
+
+func (m Model) viewImport() string {
+	s := m.theme.Title.Render(fmt.Sprintf("Import %s into %s", m.importPath, m.importTable)) + "\n\n"
+
+	for i, c := range m.importColumns {
+		cursor := "  "
+		if i == m.importCursor {
+			cursor = "> "
+		}
+		source := m.theme.Null.Render("(skip: default)")
+		if idx := m.importMapping[i]; idx >= 0 {
+			source = fmt.Sprintf("%s (field %d)", m.importHeader[idx], idx+1)
+		}
+		line := fmt.Sprintf("%s %s ← %s", c.Name, m.theme.Help.Render(c.TypeName), source)
+		if i == m.importCursor {
+			line = m.theme.Selected.Render(line)
+		}
+		s += cursor + line + "\n"
+	}
+
+	// preview of the first rows as they would be loaded
+	var columns []db.Column
+	var picks []int
+	for i, c := range m.importColumns {
+		if idx := m.importMapping[i]; idx >= 0 {
+			columns = append(columns, c)
+			picks = append(picks, idx)
+		}
+	}
+	if len(columns) > 0 {
+		rows := make([][]db.Value, len(m.importPreview))
+		for r, rec := range m.importPreview {
+			rows[r] = make([]db.Value, len(picks))
+			for i, idx := range picks {
+				rows[r][i] = db.NullValue
+				if idx < len(rec) && csvimport.Value(rec[idx]) != nil {
+					rows[r][i] = db.Text(rec[idx].Text)
+				}
+			}
+		}
+		grid := table.RenderStyled(columns, rows, m.theme, table.Highlight{Row: -1, Col: -1})
+		if m.width > 0 {
+			grid = table.ApplyHorizontalScroll(grid, 0, m.width)
+		}
+		s += "\nPreview (first " + fmt.Sprint(len(rows)) + " rows):\n" + grid
+	}
+
+	s += "\n" + m.theme.Status.Render(m.status) + "\n"
+	s += "\n" + m.theme.Help.Render("↑/↓ pick a column, ←/→ choose its CSV field, 's' to skip it, Enter to import, Esc to cancel.") + "\n"
+	return s
+}
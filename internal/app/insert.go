@@ -0,0 +1,254 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/export"
+	"github.com/hrutik5321/dbls/internal/ui/table"
+)
+
+// ----- Messages -----
+
+type insertResultMsg struct {
+	page db.RowPage
+	err  error
+}
+
+// ----- Commands -----
+
+func insertRowCmd(client db.DB, tableName string, columns []string, values []any) tea.Cmd {
+	return func() tea.Msg {
+		page, err := client.InsertRow(context.Background(), tableName, columns, values)
+		return insertResultMsg{page: page, err: err}
+	}
+}
+
+// ----- Insert form -----
+
+// fieldState is what a form field sends.
+type fieldState int
+
+const (
+	fieldDefault fieldState = iota // column left out of the INSERT
+	fieldNull
+	fieldValue
+)
+
+type insertField struct {
+	column db.Column
+	input  textinput.Model
+	state  fieldState
+}
+
+// setState switches what the field sends; DEFAULT and NULL show as the
+// placeholder of the empty input.
+func (f *insertField) setState(st fieldState) {
+	f.state = st
+	switch st {
+	case fieldDefault:
+		f.input.Placeholder = "DEFAULT"
+	case fieldNull:
+		f.input.Placeholder = "NULL"
+	default:
+		f.input.Placeholder = ""
+		return
+	}
+	f.input.SetValue("")
+}
+
+func (m Model) openInsertForm() (tea.Model, tea.Cmd) {
+	if len(m.allColumns) == 0 {
+		return m, nil
+	}
+
+	m.insertFields = make([]insertField, len(m.allColumns))
+	for i, c := range m.allColumns {
+		in := textinput.New()
+		in.Prompt = ""
+		m.insertFields[i] = insertField{column: c, input: in}
+		m.insertFields[i].setState(fieldDefault)
+	}
+	m.insertCursor = 0
+	m.insertResult = nil
+	m.focusInsertField()
+	m.mode = modeInsert
+	m.status = "Type a value, ctrl+d for DEFAULT, ctrl+n for NULL. ↑/↓ or Tab to move, ctrl+s to insert, Esc to cancel."
+	return m, nil
+}
+
+func (m *Model) focusInsertField() {
+	for i := range m.insertFields {
+		if i == m.insertCursor {
+			m.insertFields[i].input.Focus()
+		} else {
+			m.insertFields[i].input.Blur()
+		}
+	}
+}
+
+// insertStatement returns the columns and values to insert, plus the bound
+// values as shown to the user.
+func (m Model) insertStatement() ([]string, []any, string) {
+	var (
+		columns []string
+		values  []any
+		args    []string
+	)
+	for _, f := range m.insertFields {
+		switch f.state {
+		case fieldNull:
+			columns = append(columns, f.column.Name)
+			values = append(values, nil)
+			args = append(args, fmt.Sprintf("$%d = NULL", len(columns)))
+		case fieldValue:
+			columns = append(columns, f.column.Name)
+			values = append(values, f.input.Value())
+			args = append(args, fmt.Sprintf("$%d = %s", len(columns), export.QuoteLiteral(f.input.Value())))
+		}
+	}
+	return columns, values, strings.Join(args, ", ")
+}
+
+func (m Model) updateInsertKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// showing the inserted row
+	if m.insertResult != nil {
+		switch msg.String() {
+		case "ctrl+c":
+			return m.quit()
+		case "a":
+			return m.openInsertForm()
+		case "esc", "enter", "b", "q":
+			m.insertResult = nil
+			m.mode = modeRows
+			m.loading = true
+			m.status = "Reloading page..."
+			return m, fetchRowsCmd(m.dbClient, m.selectedTable, m.queryOptions(m.offset))
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+	case "esc":
+		m.mode = modeRows
+		m.status = "Insert cancelled."
+		return m, nil
+
+	case "up", "shift+tab":
+		if m.insertCursor > 0 {
+			m.insertCursor--
+		}
+		m.focusInsertField()
+		return m, nil
+	case "down", "tab", "enter":
+		if m.insertCursor < len(m.insertFields)-1 {
+			m.insertCursor++
+		}
+		m.focusInsertField()
+		return m, nil
+
+	case "ctrl+d":
+		m.insertFields[m.insertCursor].setState(fieldDefault)
+		return m, nil
+	case "ctrl+n":
+		f := &m.insertFields[m.insertCursor]
+		if f.column.Generated {
+			m.status = f.column.Name + " is generated by the database and can only be DEFAULT."
+			return m, nil
+		}
+		f.setState(fieldNull)
+		return m, nil
+
+	case "ctrl+s":
+		columns, values, _ := m.insertStatement()
+		m.loading = true
+		m.status = "Inserting..."
+		return m.guardWrite("insert a row into "+m.selectedTable, func() tea.Cmd {
+			return insertRowCmd(m.dbClient, m.selectedTable, columns, values)
+		})
+	}
+
+	f := &m.insertFields[m.insertCursor]
+	if f.column.Generated {
+		m.status = f.column.Name + " is generated by the database and can only be DEFAULT."
+		return m, nil
+	}
+	before := f.input.Value()
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	// once edited the field sends its text, even an empty string
+	if f.input.Value() != before || msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+		f.setState(fieldValue)
+	}
+	return m, cmd
+}
+
+func (m Model) viewInsert() string {
+	s := m.theme.Title.Render("Insert into "+m.selectedTable) + "\n\n"
+
+	if m.insertResult != nil {
+		s += "Inserted row:\n"
+		grid := table.RenderStyled(m.insertResult.Columns, m.insertResult.Rows, m.theme, table.Highlight{Row: -1, Col: -1})
+		if m.width > 0 {
+			grid = table.ApplyHorizontalScroll(grid, 0, m.width)
+		}
+		s += grid
+		s += "\n" + m.theme.Status.Render(m.status) + "\n"
+		s += "\n" + m.theme.Help.Render("Press 'a' to insert another row, Esc or Enter to go back to the rows.") + "\n"
+		return s
+	}
+
+	nameWidth := 0
+	for _, f := range m.insertFields {
+		nameWidth = max(nameWidth, table.Width(f.column.Name))
+	}
+
+	for i, f := range m.insertFields {
+		cursor := "  "
+		if i == m.insertCursor {
+			cursor = "> "
+		}
+		name := f.column.Name + strings.Repeat(" ", nameWidth-table.Width(f.column.Name))
+
+		var value string
+		switch {
+		case i == m.insertCursor:
+			value = f.input.View()
+		case f.state == fieldDefault:
+			value = m.theme.Help.Render("DEFAULT")
+		case f.state == fieldNull:
+			value = m.theme.Null.Render("NULL")
+		default:
+			value = f.input.Value()
+		}
+
+		var notes []string
+		notes = append(notes, f.column.TypeName)
+		if f.column.NotNull {
+			notes = append(notes, "not null")
+		}
+		switch {
+		case f.column.Generated:
+			notes = append(notes, "generated")
+		case f.column.Default != "":
+			notes = append(notes, "default "+f.column.Default)
+		}
+
+		s += fmt.Sprintf("%s%s  %s  %s\n", cursor, name, value, m.theme.Help.Render("("+strings.Join(notes, ", ")+")"))
+	}
+
+	columns, _, args := m.insertStatement()
+	s += "\n" + db.InsertSQL(m.selectedTable, columns) + "\n"
+	if args != "" {
+		s += m.theme.Help.Render("  -- "+args) + "\n"
+	}
+
+	s += "\n" + m.theme.Status.Render(m.status) + "\n"
+	return s
+}
@@ -0,0 +1,245 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/ui/jsonview"
+	"github.com/hrutik5321/dbls/internal/ui/table"
+	"github.com/mattn/go-runewidth"
+)
+
+// ----- Cell cursor -----
+
+func (m *Model) clampCursor() {
+	if m.rowCursor >= len(m.rows) {
+		m.rowCursor = len(m.rows) - 1
+	}
+	if m.rowCursor < 0 {
+		m.rowCursor = 0
+	}
+	if m.colCursor >= len(m.columns) {
+		m.colCursor = len(m.columns) - 1
+	}
+	if m.colCursor < 0 {
+		m.colCursor = 0
+	}
+}
+
+// cellAt returns the value under the cursor and whether there is one.
+func (m Model) cellAt() (db.Value, bool) {
+	if m.rowCursor >= len(m.rows) || m.colCursor >= len(m.columns) {
+		return db.Value{}, false
+	}
+	row := m.rows[m.rowCursor]
+	if m.colCursor >= len(row) {
+		return db.Value{}, false
+	}
+	return row[m.colCursor], true
+}
+
+// cursorLine describes the cell under the cursor with a one-line preview.
+func (m Model) cursorLine() string {
+	value, ok := m.cellAt()
+	if !ok {
+		return "Cursor: (empty)"
+	}
+	preview := strings.ReplaceAll(table.CellText(value), "\n", " ")
+	if table.Width(preview) > 60 {
+		preview = runewidth.Truncate(preview, 60, "…")
+	}
+	return fmt.Sprintf("Cursor: row %d, column %s (%s) = %s", m.offset+m.rowCursor+1, m.columns[m.colCursor].Name, m.columns[m.colCursor].TypeName, preview)
+}
+
+// markCursorRow prefixes every line of a rendered table with a gutter that
+// points at the data row under the cursor and flags marked rows.
+func markCursorRow(rendered string, cursor int, marks []bool) string {
+	lines := strings.Split(strings.TrimSuffix(rendered, "\n"), "\n")
+
+	// border, header, border, then one line per row
+	const firstRow = 3
+	for i, line := range lines {
+		gutter := []byte("  ")
+		if r := i - firstRow; r >= 0 && r < len(marks) {
+			if r == cursor {
+				gutter[0] = '>'
+			}
+			if marks[r] {
+				gutter[1] = '*'
+			}
+		}
+		lines[i] = string(gutter) + line
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// ----- Cell inspector -----
+
+func (m Model) openInspector() (tea.Model, tea.Cmd) {
+	value, ok := m.cellAt()
+	if !ok {
+		return m, nil
+	}
+
+	m.inspectColumn = m.columns[m.colCursor].Name
+	m.inspectRow = m.offset + m.rowCursor + 1
+	m.inspectValue = table.CellText(value)
+	m.inspectTree = nil
+	m.inspectCursor = 0
+
+	if !value.Null && m.columns[m.colCursor].Class == db.ClassJSON {
+		if tree, err := jsonview.Parse(value.Display); err == nil {
+			m.inspectTree = tree
+		}
+	}
+
+	m.mode = modeCell
+	if m.inspectTree != nil {
+		m.status = "↑/↓ to move, Enter/Space to fold, 'e'/'c' to expand/collapse all, Esc to go back."
+	} else {
+		m.status = "Esc to go back."
+	}
+	return m, nil
+}
+
+func (m Model) updateCellKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+	case "esc", "b", "q":
+		m.mode = modeRows
+		m.status = "Back to rows."
+		return m, nil
+	}
+
+	if m.inspectTree == nil {
+		return m, nil
+	}
+
+	lines := jsonview.Lines(m.inspectTree, m.theme.JSON)
+	switch msg.String() {
+	case "up", "k":
+		if m.inspectCursor > 0 {
+			m.inspectCursor--
+		}
+	case "down", "j":
+		if m.inspectCursor < len(lines)-1 {
+			m.inspectCursor++
+		}
+	case "enter", " ":
+		if n := lines[m.inspectCursor].Node; n != nil {
+			n.Collapsed = !n.Collapsed
+		}
+	case "e":
+		m.inspectTree.SetCollapsed(false)
+	case "c":
+		m.inspectTree.SetCollapsed(true)
+		m.inspectCursor = 0
+	}
+
+	if n := len(jsonview.Lines(m.inspectTree, m.theme.JSON)); m.inspectCursor >= n {
+		m.inspectCursor = n - 1
+	}
+	return m, nil
+}
+
+func (m Model) viewCell() string {
+	s := m.theme.Title.Render(fmt.Sprintf("Table %s, row %d, column %s", m.selectedTable, m.inspectRow, m.inspectColumn)) + "\n\n"
+
+	if m.inspectTree == nil {
+		s += m.inspectValue + "\n"
+		s += "\n" + m.theme.Status.Render(m.status) + "\n"
+		return s
+	}
+
+	lines := jsonview.Lines(m.inspectTree, m.theme.JSON)
+
+	// keep the cursor on screen when the document is taller than the terminal
+	start, end := 0, len(lines)
+	if visible := m.height - 6; m.height > 0 && visible > 0 && len(lines) > visible {
+		start = m.inspectCursor - visible/2
+		if start < 0 {
+			start = 0
+		}
+		end = start + visible
+		if end > len(lines) {
+			end = len(lines)
+			start = end - visible
+		}
+	}
+
+	for i := start; i < end; i++ {
+		cursor := "  "
+		if i == m.inspectCursor {
+			cursor = "> "
+		}
+		s += cursor + lines[i].Text + "\n"
+	}
+
+	s += "\n" + m.theme.Status.Render(m.status) + "\n"
+	return s
+}
+
+// ----- JSON path extraction -----
+
+func (m Model) updatePathKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.editingPath = false
+		m.jsonPath = nil
+		m.status = "JSON path cleared. Press '.' to extract again."
+		return m, nil
+
+	case "enter":
+		path, err := jsonview.CompilePath(m.pathInput.Value())
+		if err != nil {
+			m.status = "Invalid path: " + err.Error()
+			return m, nil
+		}
+		m.editingPath = false
+		m.jsonPath = &path
+		m.pathColumn = m.columns[m.colCursor].Name
+		m.status = fmt.Sprintf("Extracting %s from column %s.", path, m.pathColumn)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.pathInput, cmd = m.pathInput.Update(msg)
+	return m, cmd
+}
+
+// withPathColumn returns the page with an extra column holding the JSON path
+// evaluated against pathColumn for every row.
+func (m Model) withPathColumn() ([]db.Column, [][]db.Value) {
+	src := -1
+	for i, c := range m.columns {
+		if c.Name == m.pathColumn {
+			src = i
+			break
+		}
+	}
+	if src < 0 {
+		return m.columns, m.rows
+	}
+
+	columns := append(append([]db.Column{}, m.columns...), db.Column{
+		Name:     m.pathColumn + m.jsonPath.String(),
+		TypeName: "json",
+		Class:    db.ClassJSON,
+	})
+	rows := make([][]db.Value, len(m.rows))
+	for i, row := range m.rows {
+		extracted := db.NullValue
+		if src < len(row) && !row[src].Null {
+			if res, err := m.jsonPath.Eval(row[src].Display); err != nil {
+				extracted = db.Text("error: " + err.Error())
+			} else {
+				extracted = db.Text(strings.Join(res, ", "))
+			}
+		}
+		rows[i] = append(append([]db.Value{}, row...), extracted)
+	}
+	return columns, rows
+}
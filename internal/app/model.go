@@ -7,8 +7,15 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hrutik5321/dbls/internal/audit"
+	"github.com/hrutik5321/dbls/internal/config"
+	"github.com/hrutik5321/dbls/internal/csvimport"
 	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/export"
+	"github.com/hrutik5321/dbls/internal/ui/jsonview"
 	"github.com/hrutik5321/dbls/internal/ui/table"
+	"github.com/hrutik5321/dbls/internal/ui/theme"
 )
 
 // ----- Modes -----
@@ -19,6 +26,14 @@ const (
 	modeForm mode = iota
 	modeTables
 	modeRows
+	modeCell
+	modeColumns
+	modeImport
+	modeInsert
+	modeDelete // preview and confirmation of a DELETE
+	modeAudit
+	modeFilter // structured filter builder
+	modeSearch // hits of a search over every table
 )
 
 // ----- Messages from async DB commands -----
@@ -28,8 +43,8 @@ type dbResultMsg struct {
 }
 
 type deleteResultMsg struct {
-	affected int64
-	err      error
+	images []db.RowImage // deleted rows, for undo
+	err    error
 }
 
 type tablesResultMsg struct {
@@ -37,6 +52,12 @@ type tablesResultMsg struct {
 	err    error
 }
 
+type columnsResultMsg struct {
+	columns []db.Column
+	keys    []string
+	err     error
+}
+
 type rowsResultMsg struct {
 	page db.RowPage
 	err  error
@@ -46,6 +67,8 @@ type rowsResultMsg struct {
 
 type Model struct {
 	dbClient db.DB
+	opts     Options
+	theme    theme.Theme
 
 	// form inputs
 	hostInput textinput.Model
@@ -64,30 +87,161 @@ type Model struct {
 	tableCursor   int
 	selectedTable string
 
-	columns []string
-	rows    [][]string
+	columns []db.Column
+	rows    [][]db.Value
+
+	// column layout: every column of the table, and the chosen projection
+	// (nil means all columns in table order)
+	allColumns     []db.Column
+	visibleColumns []string
+	hiddenColumns  []string
+	layouts        config.Layouts
+
+	// column chooser
+	chooser       []chooserItem
+	chooserCursor int
 
 	// pagination
 	pageSize  int
 	offset    int
 	totalRows int
 
-	// filtering
+	// filtering: a raw WHERE clause, or a structured filter built from
+	// chips (only one of them is set)
 	filter        string
 	filterInput   textinput.Model
 	editingFilter bool
+	filterWhere   db.Expr
+	chips         []filterChip
+	appliedChips  []filterChip // chips of filterWhere, restored on cancel
+	chipCursor    int
+	chipField     int
+
+	// filter checked by the database before it is applied
+	checkingFilter bool
+	filterErr      error  // why the last filter was rejected
+	filterRejected string // raw filter the error refers to
+
+	// search: text looked for in every column, ANDed with the filter
+	search       string
+	searchInput  textinput.Model
+	searching    bool // prompt open
+	searchAll    bool // prompt searches every table
+	searchHits   []db.SearchHit
+	searchCursor int
+	searchText   string // text the hits are for
+	searchFrom   mode   // screen to go back to from the hits
 
 	// delete
+	deleteInput   textinput.Model
 	editingDelete bool
+	deleteWhere   string
+	deletePreview *db.RowPage
+	deleteConfirm textinput.Model
+
+	// display
+	binaryFormat db.BinaryFormat
+
+	// cell cursor
+	rowCursor int
+	colCursor int
+
+	// cell inspector
+	inspectColumn string
+	inspectRow    int
+	inspectValue  string
+	inspectTree   *jsonview.Node
+	inspectCursor int
+
+	// jq-style path extraction over one column
+	jsonPath    *jsonview.Path
+	pathColumn  string
+	pathInput   textinput.Model
+	editingPath bool
+
+	// marked rows by position in the filtered result, and a range being
+	// selected from visualFrom to the cursor
+	marked        map[int][]db.Value
+	visual        bool
+	visualFrom    int
+	pendingDelete []db.RowKey // marked rows waiting for y/n
+
+	// export
+	exportInput      textinput.Model
+	exportFormat     export.Format
+	exportScope      exportScope
+	exportMultiRow   bool // SQL: one INSERT per batch
+	exportOnConflict bool // SQL: ON CONFLICT DO NOTHING
+	exporting        bool // prompt open
+	exportRunning    bool
+
+	// editing: columns that identify a row, and the cell editor
+	keyColumns    []string
+	editInput     textinput.Model
+	editingCell   bool
+	editNull      bool
+	pendingUpdate *pendingUpdate
+
+	// insert form
+	insertFields []insertField
+	insertCursor int
+	insertResult *db.RowPage
+
+	// production: a write waiting for the database name
+	guard      *guardedWrite
+	guardInput textinput.Model
+
+	// transaction session: open on the server, writes done in it, and a
+	// y/n question about it
+	txOpen    bool
+	txChanges []string
+	txPrompt  txPrompt
+
+	// journal length when the transaction began, and entries undone in it,
+	// to put the journal back on rollback
+	txJournal int
+	txUndone  []journalEntry
+
+	// undo: before-images of this session's edits and deletes, newest
+	// last, and whether the last one is waiting for y/n
+	journal     []journalEntry
+	journalSeq  int
+	undoPending bool
+
+	// audit log viewer, newest entry first
+	auditEntries []audit.Entry
+	auditCursor  int
+	auditDetail  bool
+	auditFrom    mode  // screen to go back to
+	auditErr     error // last write that ran but could not be logged
+
+	// clipboard: 'y' was pressed, waiting for what to copy
+	yankPending bool
+
+	// CSV import
+	importInput   textinput.Model
+	importing     bool // file prompt open on the tables screen
+	importTable   string
+	importPath    string
+	importColumns []db.Column
+	importHeader  []string
+	importPreview [][]csvimport.Field
+	importMapping []int // CSV field per table column, -1 to skip
+	importCursor  int
+	importRunning bool
+
+	// frozen columns: primary key by default, toggled per column name
+	pins map[string]bool
 
 	// terminal / scroll
-	width       int
-	horizOffset int
+	width     int
+	height    int
+	colOffset int // scrollable (non-frozen) columns skipped on the left
 }
 
 // ----- Initial model -----
 
-func initialModel(dbClient db.DB) Model {
+func initialModel(dbClient db.DB, opts Options) Model {
 	host := textinput.New()
 	host.Placeholder = "localhost"
 	host.Prompt = "Host: "
@@ -114,8 +268,36 @@ func initialModel(dbClient db.DB) Model {
 	filterInput.Placeholder = "id > 10 AND status = 'active'"
 	filterInput.Prompt = "WHERE "
 
+	exportInput := textinput.New()
+	exportInput.Prompt = "File: "
+
+	importInput := textinput.New()
+	importInput.Prompt = "CSV: "
+
+	editInput := textinput.New()
+	editInput.Prompt = "= "
+
+	deleteInput := textinput.New()
+	deleteInput.Placeholder = "id = 42"
+	deleteInput.Prompt = "DELETE WHERE "
+
+	deleteConfirm := textinput.New()
+	deleteConfirm.Prompt = "> "
+
+	guardInput := textinput.New()
+	guardInput.Prompt = "> "
+
+	searchInput := textinput.New()
+	searchInput.Prompt = "Find: "
+
+	pathInput := textinput.New()
+	pathInput.Placeholder = ".items[0].name"
+	pathInput.Prompt = "PATH "
+
 	m := Model{
 		dbClient:   dbClient,
+		opts:       opts,
+		theme:      opts.Theme,
 		hostInput:  host,
 		portInput:  port,
 		userInput:  user,
@@ -131,9 +313,34 @@ func initialModel(dbClient db.DB) Model {
 		filter:        "",
 		filterInput:   filterInput,
 		editingFilter: false,
+
+		pathInput:   pathInput,
+		searchInput: searchInput,
+		exportInput: exportInput,
+		importInput: importInput,
+		editInput:   editInput,
+
+		deleteInput:   deleteInput,
+		deleteConfirm: deleteConfirm,
+		guardInput:    guardInput,
+	}
+
+	if p := opts.Profile; p != (config.Profile{}) {
+		m.hostInput.SetValue(p.Host)
+		m.portInput.SetValue(p.Port)
+		m.userInput.SetValue(p.User)
+		m.dbInput.SetValue(p.Database)
+		m.focusIndex = 3
+		m.status = "Profile " + opts.ProfileName + ": enter the password and press Enter to connect."
+	}
+
+	layouts, err := config.LoadLayouts()
+	if err != nil {
+		m.status += " (could not read saved column layouts: " + err.Error() + ")"
 	}
+	m.layouts = layouts
 
-	m.hostInput.Focus()
+	m.updateFocus()
 	return m
 }
 
@@ -150,10 +357,10 @@ func connectCmd(client db.DB, cfg db.ConnConfig) tea.Cmd {
 	}
 }
 
-func deleteRowsCmd(client db.DB, tableName string, where string) tea.Cmd {
+func deleteRowsCmd(client db.DB, tableName string, where string, expect int64) tea.Cmd {
 	return func() tea.Msg {
-		affected, err := client.DeleteRows(context.Background(), tableName, where)
-		return deleteResultMsg{affected: affected, err: err}
+		images, err := client.DeleteRows(context.Background(), tableName, where, expect)
+		return deleteResultMsg{images: images, err: err}
 	}
 }
 
@@ -164,6 +371,30 @@ func listTablesCmd(client db.DB) tea.Cmd {
 	}
 }
 
+// queryOptions returns the fetch options for the page starting at offset.
+func (m Model) queryOptions(offset int) db.QueryOptions {
+	return db.QueryOptions{
+		Limit:  m.pageSize,
+		Offset: offset,
+		Filter: m.filter,
+		Where:  m.whereExpr(),
+		Binary: m.binaryFormat,
+
+		Columns: m.visibleColumns,
+	}
+}
+
+func listColumnsCmd(client db.DB, tableName string) tea.Cmd {
+	return func() tea.Msg {
+		columns, err := client.ListColumns(context.Background(), tableName)
+		if err != nil {
+			return columnsResultMsg{err: err}
+		}
+		keys, err := client.KeyColumns(context.Background(), tableName)
+		return columnsResultMsg{columns: columns, keys: keys, err: err}
+	}
+}
+
 func fetchRowsCmd(client db.DB, tableName string, opts db.QueryOptions) tea.Cmd {
 	return func() tea.Msg {
 		page, err := client.FetchRows(context.Background(), tableName, opts)
@@ -205,40 +436,79 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case deleteResultMsg:
+	case deletePreviewMsg:
 		m.loading = false
 		if msg.err != nil {
-			m.status = "Delete failed: " + msg.err.Error()
+			// keep the prompt open to fix the clause
+			m.status = "Invalid WHERE clause: " + msg.err.Error()
+			return m, nil
+		}
+		m.editingDelete = false
+		m.deleteWhere = msg.where
+		m.deletePreview = &msg.page
+		m.deleteConfirm.SetValue("")
+		m.deleteConfirm.Focus()
+		m.mode = modeDelete
+		if msg.page.TotalRows == 0 {
+			m.status = "Nothing to delete. Esc to go back."
+		} else {
+			m.status = m.deleteConfirmHint()
+		}
+		return m, nil
+
+	case deleteResultMsg:
+		m.loading = false
+		if err := m.logged(msg.err); err != nil {
+			m.status = "Delete failed: " + err.Error()
 			// stay in rows mode so user can adjust WHERE or try again
 			m.mode = modeRows
 			return m, nil
 		}
 
-		m.status = fmt.Sprintf("Deleted %d row(s). Reloading page...", msg.affected)
+		m.clearMarks()
+		affected := int64(len(msg.images))
+		m.recordChange("DELETE FROM %s: %s", m.selectedTable, plural(affected))
+		m.journalDelete(msg.images)
+		m.status = fmt.Sprintf("Deleted %d row(s). Reloading page...", affected)
 		// reload current page with same filter & offset (offset may adjust logically via rowsResultMsg)
 		m.loading = true
 		return m, fetchRowsCmd(
 			m.dbClient,
 			m.selectedTable,
-			db.QueryOptions{
-				Limit:  m.pageSize,
-				Offset: m.offset,
-				Filter: m.filter,
-			},
+			m.queryOptions(m.offset),
 		)
 
+	// table columns: apply the saved layout, then fetch the first page
+	case columnsResultMsg:
+		if msg.err != nil {
+			m.loading = false
+			m.status = "Failed to fetch columns: " + msg.err.Error()
+			m.mode = modeTables
+			return m, nil
+		}
+		m.allColumns = msg.columns
+		m.keyColumns = msg.keys
+		m.applyLayout()
+		return m, fetchRowsCmd(m.dbClient, m.selectedTable, m.queryOptions(m.offset))
+
 	// rows result (with pagination info)
 	case rowsResultMsg:
 		m.loading = false
 		if msg.err != nil {
 			m.status = "Failed to fetch rows: " + msg.err.Error()
-			m.mode = modeTables
+			// keep the rows on screen; only a table that never loaded
+			// goes back to the list
+			if m.mode != modeRows {
+				m.mode = modeTables
+			}
 			return m, nil
 		}
 		m.columns = msg.page.Columns
 		m.rows = msg.page.Rows
 		m.totalRows = msg.page.TotalRows
 		m.offset = msg.page.Offset
+		m.visual = false
+		m.clampCursor()
 		m.status = fmt.Sprintf(
 			"Showing rows (page size %d). Press 'b' to go back, 'n'/'p' for next/prev page, '/' to filter.",
 			m.pageSize,
@@ -246,9 +516,110 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mode = modeRows
 		return m, nil
 
+	case exportProgressMsg:
+		m.status = fmt.Sprintf("Exporting... %d of %d rows written.", msg.written, msg.total)
+		return m, waitForProgress(msg.progress)
+
+	case importPreviewMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = "Cannot import: " + msg.err.Error()
+			return m, nil
+		}
+		m.importColumns = msg.columns
+		m.importHeader = msg.header
+		m.importPreview = msg.rows
+		m.importMapping = csvimport.Match(msg.header, msg.columns)
+		m.importCursor = 0
+		m.mode = modeImport
+
+		matched := 0
+		for _, idx := range m.importMapping {
+			if idx >= 0 {
+				matched++
+			}
+		}
+		m.status = fmt.Sprintf("Matched %d of %d columns by header name.", matched, len(msg.columns))
+		return m, nil
+
+	case importProgressMsg:
+		m.status = fmt.Sprintf("Importing... %d rows sent.", msg.rows)
+		return m, waitForProgress(msg.progress)
+
+	case importDoneMsg:
+		m.importRunning = false
+		if err := m.logged(msg.err); err != nil {
+			at := ""
+			if msg.line > 0 {
+				at = fmt.Sprintf(" at line %d", msg.line)
+			}
+			m.status = fmt.Sprintf("Import failed%s: %s. Nothing was imported.", at, err.Error())
+			return m, nil
+		}
+		m.mode = modeTables
+		m.recordChange("COPY %s FROM %s: %s", m.importTable, m.importPath, plural(msg.rows))
+		m.status = fmt.Sprintf("Imported %d row(s) into %s.", msg.rows, m.importTable)
+		return m, nil
+
+	case insertResultMsg:
+		m.loading = false
+		if err := m.logged(msg.err); err != nil {
+			m.status = "Insert failed: " + err.Error()
+			return m, nil
+		}
+		m.insertResult = &msg.page
+		m.recordChange("INSERT INTO %s: 1 row", m.selectedTable)
+		m.status = "Row inserted."
+		return m, nil
+
+	case updateResultMsg:
+		m.loading = false
+		if err := m.logged(msg.err); err != nil {
+			m.status = "Update failed: " + err.Error()
+			return m, nil
+		}
+		if affected := int64(len(msg.images)); affected == 0 {
+			m.status = "No row updated: it was changed or deleted in the meantime. Reloading page..."
+		} else {
+			m.recordChange("UPDATE %s SET %s: %s", m.selectedTable, msg.column, plural(affected))
+			m.journalUpdate(msg.column, msg.value, msg.images)
+			m.status = fmt.Sprintf("Updated %d row(s). Reloading page...", affected)
+		}
+		m.loading = true
+		return m, fetchRowsCmd(m.dbClient, m.selectedTable, m.queryOptions(m.offset))
+
+	case txResultMsg:
+		return m.txDone(msg)
+
+	case undoResultMsg:
+		return m.undoDone(msg)
+
+	case auditLoadedMsg:
+		return m.auditLoaded(msg)
+
+	case searchHitsMsg:
+		return m.searchDone(msg)
+
+	case filterCheckMsg:
+		return m.filterChecked(msg)
+
+	case yankDoneMsg:
+		m.status = "Copied " + msg.what + " to the clipboard."
+		return m, nil
+
+	case exportDoneMsg:
+		m.exportRunning = false
+		if msg.err != nil {
+			m.status = "Export failed: " + msg.err.Error()
+			return m, nil
+		}
+		m.status = fmt.Sprintf("Exported %d row(s) to %s.", msg.written, msg.path)
+		return m, nil
+
 	// window size
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
+		m.height = msg.Height
 		return m, nil
 
 	case tea.KeyMsg:
@@ -261,6 +632,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // ----- Key handling dispatcher -----
 
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.guard != nil {
+		return m.updateGuardKey(msg)
+	}
+	if m.txPrompt != txPromptNone {
+		return m.updateTxPromptKey(msg)
+	}
+
 	switch m.mode {
 	case modeForm:
 		return m.updateFormKey(msg)
@@ -268,6 +646,22 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.updateTablesKey(msg)
 	case modeRows:
 		return m.updateRowsKey(msg)
+	case modeCell:
+		return m.updateCellKey(msg)
+	case modeColumns:
+		return m.updateColumnsKey(msg)
+	case modeImport:
+		return m.updateImportKey(msg)
+	case modeInsert:
+		return m.updateInsertKey(msg)
+	case modeDelete:
+		return m.updateDeleteConfirmKey(msg)
+	case modeAudit:
+		return m.updateAuditKey(msg)
+	case modeFilter:
+		return m.updateFilterBuilderKey(msg)
+	case modeSearch:
+		return m.updateSearchHitsKey(msg)
 	default:
 		return m, nil
 	}
@@ -302,6 +696,8 @@ func (m Model) updateFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					User:     m.userInput.Value(),
 					Password: m.passInput.Value(),
 					Database: m.dbInput.Value(),
+					TimeZone: m.opts.TimeZone,
+					ReadOnly: m.opts.ReadOnly,
 				},
 			)
 		}
@@ -342,9 +738,20 @@ func (m Model) updateFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // --- tables mode ---
 
 func (m Model) updateTablesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.importing {
+		return m.updateImportPromptKey(msg)
+	}
+	if m.searching {
+		return m.updateSearchKey(msg)
+	}
+
+	if m, cmd, ok := m.updateTxKey(msg); ok {
+		return m, cmd
+	}
+
 	switch msg.String() {
 	case "ctrl+c", "esc", "q":
-		return m, tea.Quit
+		return m.quit()
 	case "up":
 		if m.tableCursor > 0 {
 			m.tableCursor--
@@ -357,51 +764,45 @@ func (m Model) updateTablesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(m.tableNames) == 0 {
 			return m, nil
 		}
-		m.selectedTable = m.tableNames[m.tableCursor]
-		m.loading = true
-		m.offset = 0
-		m.horizOffset = 0
-		m.filter = ""
-		m.status = "Fetching rows from " + m.selectedTable + "..."
-		return m, fetchRowsCmd(
-			m.dbClient,
-			m.selectedTable,
-			db.QueryOptions{
-				Limit:  m.pageSize,
-				Offset: m.offset,
-				Filter: m.filter,
-			},
-		)
+		return m.openTable(m.tableNames[m.tableCursor])
+	case "s":
+		return m.openSearch(true)
+	case "i":
+		if !m.allowWrite() {
+			return m, nil
+		}
+		return m.openImport()
+	case "L":
+		return m.openAudit()
 	}
 	return m, nil
 }
 
+// openTable starts browsing a table from its first page, with no filter.
+func (m Model) openTable(name string) (tea.Model, tea.Cmd) {
+	m.selectedTable = name
+	m.loading = true
+	m.offset = 0
+	m.colOffset = 0
+	m.pins = make(map[string]bool)
+	m.rowCursor = 0
+	m.colCursor = 0
+	m.filter = ""
+	m.filterWhere, m.chips, m.appliedChips = nil, nil, nil
+	m.checkingFilter, m.filterErr = false, nil
+	m.search = ""
+	m.jsonPath = nil
+	m.clearMarks()
+	m.status = "Fetching rows from " + m.selectedTable + "..."
+	return m, listColumnsCmd(m.dbClient, m.selectedTable)
+}
+
 // --- rows mode ---
 
 func (m Model) updateRowsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// editing delete WHERE clause
 	if m.editingDelete {
-		switch msg.String() {
-		case "esc", "ctrl+c":
-			m.editingDelete = false
-			m.status = "Delete cancelled. Press 'd' to delete again."
-			return m, nil
-
-		case "enter":
-			where := strings.TrimSpace(m.filterInput.Value())
-			if where == "" {
-				m.status = "WHERE clause cannot be empty for DELETE."
-				return m, nil
-			}
-			m.editingDelete = false
-			m.loading = true
-			m.status = "Deleting rows..."
-			return m, deleteRowsCmd(m.dbClient, m.selectedTable, where)
-		}
-
-		var cmd tea.Cmd
-		m.filterInput, cmd = m.filterInput.Update(msg)
-		return m, cmd
+		return m.updateDeleteKey(msg)
 	}
 
 	// editing filter
@@ -409,34 +810,23 @@ func (m Model) updateRowsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "esc", "ctrl+c":
 			m.editingFilter = false
+			m.checkingFilter = false
+			m.filterErr = nil
 			m.filter = ""
+			m.filterWhere, m.chips, m.appliedChips = nil, nil, nil
 			m.offset = 0
+			m.clearMarks()
 			m.loading = true
 			m.status = "Filter cancelled. Press '/' to filter again."
 			return m, fetchRowsCmd(
 				m.dbClient,
 				m.selectedTable,
-				db.QueryOptions{
-					Limit:  m.pageSize,
-					Offset: m.offset,
-					Filter: m.filter,
-				},
+				m.queryOptions(m.offset),
 			)
+		case "ctrl+t":
+			return m.openFilterBuilder()
 		case "enter":
-			m.filter = m.filterInput.Value()
-			m.editingFilter = false
-			m.offset = 0
-			m.loading = true
-			m.status = "Applying filter..."
-			return m, fetchRowsCmd(
-				m.dbClient,
-				m.selectedTable,
-				db.QueryOptions{
-					Limit:  m.pageSize,
-					Offset: m.offset,
-					Filter: m.filter,
-				},
-			)
+			return m.applyFilter(m.filterInput.Value(), nil)
 		}
 
 		var cmd tea.Cmd
@@ -444,47 +834,187 @@ func (m Model) updateRowsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
+	// search prompt
+	if m.searching {
+		return m.updateSearchKey(msg)
+	}
+
+	// editing JSON path
+	if m.editingPath {
+		return m.updatePathKey(msg)
+	}
+
+	// export prompt
+	if m.exporting {
+		return m.updateExportKey(msg)
+	}
+
+	// cell editor
+	if m.editingCell {
+		return m.updateEditKey(msg)
+	}
+
+	// y/n for deleting marked rows
+	if m.pendingDelete != nil {
+		return m.updateDeleteMarkedKey(msg)
+	}
+
+	// y/n for undoing the last write
+	if m.undoPending {
+		return m.updateUndoKey(msg)
+	}
+
+	// second key of a yank
+	if m.yankPending {
+		return m.updateYankKey(msg)
+	}
+
+	if m, cmd, ok := m.updateTxKey(msg); ok {
+		return m, cmd
+	}
+
 	// normal rows controls
 	switch msg.String() {
 	case "ctrl+c", "q":
-		return m, tea.Quit
+		return m.quit()
+
+	// cell cursor
+	case "up", "k":
+		if m.rowCursor > 0 {
+			m.rowCursor--
+		}
+	case "down", "j":
+		if m.rowCursor < len(m.rows)-1 {
+			m.rowCursor++
+		}
+	case "tab":
+		m.moveColCursor(1)
+	case "shift+tab":
+		m.moveColCursor(-1)
+	case "f":
+		m.togglePin()
+	case " ":
+		if m.visual {
+			m.toggleVisual()
+		} else {
+			m.toggleMark()
+		}
+	case "v":
+		m.toggleVisual()
+	case "esc":
+		if m.visual {
+			m.visual = false
+			m.status = "Selection cancelled."
+		}
+	case "D":
+		if !m.allowWrite() {
+			return m, nil
+		}
+		return m.confirmDeleteMarked()
+	case "y":
+		return m.startYank()
+	case "u":
+		if !m.allowWrite() {
+			return m, nil
+		}
+		return m.openEditor()
+	case "a":
+		if !m.allowWrite() {
+			return m, nil
+		}
+		return m.openInsertForm()
+	case "U":
+		if !m.allowWrite() {
+			return m, nil
+		}
+		return m.confirmUndo()
+	case "c":
+		return m.openChooser()
+	case "s":
+		return m.openSearch(false)
+	case "L":
+		return m.openAudit()
+	case "e":
+		if m.exportRunning {
+			m.status = "An export is already running."
+			return m, nil
+		}
+		return m.openExport()
+	case "enter":
+		return m.openInspector()
+
+	// quick filter on the cell under the cursor
+	case "=":
+		return m.quickFilter(db.OpEq)
+	case "!":
+		return m.quickFilter(db.OpNe)
+	case ">":
+		return m.quickFilter(db.OpGt)
+	case "<":
+		return m.quickFilter(db.OpLt)
+	case "~":
+		return m.quickFilter(db.OpLike)
+
+	// cycle bytea display: hex -> escape -> base64
+	case "x":
+		m.binaryFormat = (m.binaryFormat + 1) % 3
+		m.loading = true
+		m.status = "Showing binary values as " + m.binaryFormat.String() + "..."
+		return m, fetchRowsCmd(m.dbClient, m.selectedTable, m.queryOptions(m.offset))
+
+	case ".":
+		if len(m.columns) == 0 {
+			return m, nil
+		}
+		m.editingPath = true
+		m.editingFilter = false
+		m.editingDelete = false
+		if m.jsonPath != nil {
+			m.pathInput.SetValue(m.jsonPath.String())
+		} else {
+			m.pathInput.SetValue(".")
+		}
+		m.pathInput.CursorEnd()
+		m.pathInput.Focus()
+		m.status = fmt.Sprintf("Enter a jq-style path to extract from column %q. Enter to apply, Esc to clear.", m.columns[m.colCursor].Name)
+		return m, nil
 
 	// remove filters
 	case "r":
 		m.filter = ""
+		m.filterWhere, m.chips, m.appliedChips = nil, nil, nil
+		m.search = ""
 		m.offset = 0
+		m.clearMarks()
 		m.loading = true
 		m.status = "Fetching rows from " + m.selectedTable + "..."
 		return m, fetchRowsCmd(
 			m.dbClient,
 			m.selectedTable,
-			db.QueryOptions{
-				Limit:  m.pageSize,
-				Offset: m.offset,
-				Filter: m.filter,
-			},
+			m.queryOptions(m.offset),
 		)
 	case "d":
-		m.editingDelete = true
-		m.editingFilter = false
-		// m.filterInput.Prompt = "DELETE WHERE "
-		m.filterInput.SetValue("")
-		m.filterInput.Focus()
-		m.status = "Enter SQL WHERE clause for DELETE (without 'WHERE'). Enter to delete, Esc to cancel."
-		return m, nil
+		if !m.allowWrite() {
+			return m, nil
+		}
+		return m.openDelete()
 
 	case "b":
 		m.mode = modeTables
 		m.status = "Use ↑/↓ and Enter to select another table."
 
 	case "/":
+		m.filterErr = nil
+		if m.filterWhere != nil {
+			return m.openFilterBuilder()
+		}
 		m.editingFilter = true
 		m.editingDelete = false
 		// m.filterInput.Prompt = "FILTER WHERE "
 		m.filterInput.Placeholder = "Add Your Filter Here"
 		m.filterInput.SetValue(m.filter)
 		m.filterInput.Focus()
-		m.status = "Enter SQL WHERE clause (without 'WHERE'). Enter to apply, Esc to cancel."
+		m.status = "Enter SQL WHERE clause (without 'WHERE'). Enter to apply, Esc to cancel, ctrl+t for the builder."
 		return m, nil
 
 	// pagination
@@ -502,11 +1032,7 @@ func (m Model) updateRowsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, fetchRowsCmd(
 			m.dbClient,
 			m.selectedTable,
-			db.QueryOptions{
-				Limit:  m.pageSize,
-				Offset: nextOffset,
-				Filter: m.filter,
-			},
+			m.queryOptions(nextOffset),
 		)
 
 	case "p":
@@ -526,28 +1052,18 @@ func (m Model) updateRowsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, fetchRowsCmd(
 			m.dbClient,
 			m.selectedTable,
-			db.QueryOptions{
-				Limit:  m.pageSize,
-				Offset: prevOffset,
-				Filter: m.filter,
-			},
+			m.queryOptions(prevOffset),
 		)
 
-	// fast horizontal scroll
+	// horizontal scroll, one column at a time
 	case "left", "h":
-		m.horizOffset -= 4
-		if m.horizOffset < 0 {
-			m.horizOffset = 0
-		}
+		m.scrollColumns(-1)
 	case "right", "l":
-		m.horizOffset += 4
+		m.scrollColumns(1)
 	case "shift+left":
-		m.horizOffset -= 16
-		if m.horizOffset < 0 {
-			m.horizOffset = 0
-		}
+		m.colOffset = 0
 	case "shift+right":
-		m.horizOffset += 16
+		m.scrollColumns(len(m.columns))
 	}
 
 	return m, nil
@@ -583,16 +1099,39 @@ func (m *Model) updateFocus() []tea.Cmd {
 // ----- Views -----
 
 func (m Model) View() string {
+	var s string
 	switch m.mode {
 	case modeForm:
 		return m.viewForm()
 	case modeTables:
-		return m.viewTables()
+		s = m.viewTables()
 	case modeRows:
-		return m.viewRows()
+		s = m.viewRows()
+	case modeCell:
+		s = m.viewCell()
+	case modeColumns:
+		s = m.viewColumns()
+	case modeImport:
+		s = m.viewImport()
+	case modeInsert:
+		s = m.viewInsert()
+	case modeDelete:
+		s = m.viewDelete()
+	case modeAudit:
+		s = m.viewAudit()
+	case modeFilter:
+		s = m.viewFilterBuilder()
+	case modeSearch:
+		s = m.viewSearchHits()
 	default:
 		return "Unknown state"
 	}
+
+	s += m.viewTx()
+	if m.guard != nil {
+		s += m.inputBox("Confirm on production", m.guardInput.View())
+	}
+	return m.banner() + s
 }
 
 func (m Model) viewForm() string {
@@ -602,56 +1141,91 @@ func (m Model) viewForm() string {
 	}
 
 	return fmt.Sprintf(
-		"Enter Postgres Credentials:\n\n%s\n%s\n%s\n%s\n%s\n\n%s%s\n\n(ctrl+c/esc to quit)\n",
+		"%s\n\n%s\n%s\n%s\n%s\n%s\n\n%s%s\n\n%s\n",
+		m.theme.Title.Render("Enter Postgres Credentials:"),
 		m.hostInput.View(),
 		m.portInput.View(),
 		m.userInput.View(),
 		m.passInput.View(),
 		m.dbInput.View(),
-		m.status,
+		m.theme.Status.Render(m.status),
 		loading,
+		m.theme.Help.Render("(ctrl+c/esc to quit)"),
 	)
 }
 
 func (m Model) viewTables() string {
-	s := "Connected.\n\nTables in public schema:\n\n"
+	s := "Connected.\n\n" + m.theme.Title.Render("Tables in public schema:") + "\n\n"
 
 	if len(m.tableNames) == 0 && !m.loading {
 		s += "  (no tables found)\n"
 	}
 
 	for i, t := range m.tableNames {
-		cursor := "  "
 		if i == m.tableCursor {
-			cursor = "> "
+			s += "> " + m.theme.Selected.Render(t) + "\n"
+		} else {
+			s += "  " + t + "\n"
 		}
-		s += fmt.Sprintf("%s%s\n", cursor, t)
 	}
 
 	if m.loading {
 		s += "\nLoading...\n"
 	}
 
-	s += "\n" + m.status + "\n"
-	s += "\nUse ↑/↓ and Enter. Press q or ctrl+c to quit.\n"
+	if m.importing {
+		s += m.inputBox("Import", m.importInput.View())
+	}
+
+	if m.searching {
+		s += m.inputBox(m.searchLabel(), m.searchInput.View())
+	}
+
+	s += "\n" + m.theme.Status.Render(m.status) + "\n"
+	s += "\n" + m.theme.Help.Render("Use ↑/↓ and Enter. Press 'i' to import a CSV file, 's' to search every table, 'T' to begin a transaction, 'L' for the audit log, q or ctrl+c to quit.") + "\n"
 
 	return s
 }
 
+// inputBox draws a labelled frame around a text input.
+func (m Model) inputBox(label, input string) string {
+	top := "┌" + strings.Repeat("─", lipgloss.Width(input)+2) + "┐"
+	middle := "│ " + input + " "
+	bottom := "└" + strings.Repeat("─", lipgloss.Width(input)+2) + "┘"
+
+	return "\n" + m.theme.Title.Render(label) + "\n" + m.theme.Border.Render(top) + "\n" + middle + "\n" + m.theme.Border.Render(bottom) + "\n"
+}
+
 func (m Model) viewRows() string {
-	s := fmt.Sprintf("Rows from table: %s\n\n", m.selectedTable)
+	s := m.theme.Title.Render("Rows from table: "+m.selectedTable) + "\n\n"
 
-	if m.filter != "" {
-		s += fmt.Sprintf("Active filter: WHERE %s\n\n", m.filter)
+	if filter := m.filterText(); filter != "" {
+		s += fmt.Sprintf("Active filter: WHERE %s\n\n", filter)
+	}
+	if m.search != "" {
+		s += fmt.Sprintf("Search: %s in every column\n\n", db.QuoteLiteral(m.search))
 	}
 
 	if len(m.columns) == 0 {
 		s += "(No rows or columns found)\n"
 	} else {
-		s += table.Render(m.columns, m.rows)
+		columns, rows, cursorCol := m.visibleGrid()
+		grid := table.RenderStyled(columns, rows, m.theme, table.Highlight{Row: m.rowCursor, Col: cursorCol, Match: m.search})
+		if m.width > 0 {
+			// room for the cursor gutter
+			grid = table.ApplyHorizontalScroll(grid, 0, m.width-2)
+		}
+		s += markCursorRow(grid, m.rowCursor, m.pageMarks())
+		s += "\n" + m.cursorLine() + "\n"
+		if frozen := m.frozenNames(); len(frozen) > 0 {
+			s += "Frozen: " + strings.Join(frozen, ", ") + "\n"
+		}
+		if len(m.marked) > 0 {
+			s += fmt.Sprintf("Marked: %d row(s)\n", len(m.marked))
+		}
 	}
 
-	if m.filter != "" {
+	if m.filterText() != "" || m.search != "" {
 		s += "\nPress 'r' to refresh the table (clear filter)\n"
 	}
 
@@ -674,33 +1248,34 @@ func (m Model) viewRows() string {
 	}
 
 	if m.editingFilter {
-		// 	input := m.filterInput.View()
-		// label := " DELETE WHERE "
-		// s += "\nFilter: " + m.filterInput.View() + "\n"
-		input := m.filterInput.View()
-		label := "Filter"
+		s += m.inputBox("Filter", m.filterInput.View())
+		s += m.viewFilterErr()
+	}
 
-		top := "┌" + strings.Repeat("─", len(input)+2) + "┐"
-		middle := "│ " + input + " "
-		bottom := "└" + strings.Repeat("─", len(input)+2) + "┘"
+	if m.editingPath {
+		s += m.inputBox("JSON path", m.pathInput.View())
+	}
 
-		s += "\n" + label + "\n" + top + "\n" + middle + "\n" + bottom + "\n"
+	if m.searching {
+		s += m.inputBox(m.searchLabel(), m.searchInput.View())
 	}
 
-	if m.editingDelete {
-		input := m.filterInput.View()
-		label := " DELETE "
+	if m.exporting {
+		s += m.inputBox("Export", m.exportInput.View())
+	}
 
-		top := "┌" + strings.Repeat("─", len(input)+2) + "┐"
-		middle := "│ " + input + " "
-		bottom := "└" + strings.Repeat("─", len(input)+2) + "┘"
+	if m.editingDelete {
+		s += m.inputBox("DELETE", m.deleteInput.View())
+	}
 
-		s += "\n" + label + "\n" + top + "\n" + middle + "\n" + bottom + "\n"
+	if m.editingCell {
+		s += m.inputBox("UPDATE "+m.columns[m.colCursor].Name, m.editInput.View())
 	}
 
-	s += "\n" + m.status + "\n"
-	s += "\nPress 'b' to go back to tables, 'q' or ctrl+c to quit. Use n/p for next/prev page, '/' to filter, ←/→ or h/l to scroll horizontally.\n"
+	s += "\n" + m.theme.Status.Render(m.status) + "\n"
+	s += "\n" + m.theme.Help.Render("Press 'b' to go back to tables, 'q' or ctrl+c to quit. Use n/p for next/prev page, '/' to filter, 's' to search, ←/→ or h/l to scroll columns, 'f' to freeze/unfreeze a column, 'c' to choose columns, 'e' to export, 'y' to copy, 'L' for the audit log.") + "\n"
+	s += m.theme.Help.Render("↑/↓ and Tab/Shift+Tab move the cell cursor, Enter inspects a cell, =/!/>/</~ filter on it, '.' extracts a JSON path, 'x' switches binary display ("+m.binaryFormat.String()+").") + "\n"
+	s += m.theme.Help.Render("Space or 'v' (range) marks rows, 'u' edits a cell, 'a' adds a row, 'd' deletes by WHERE, 'D' deletes the marked rows, 'U' undoes the last edit or delete, 'T' begins a transaction.") + "\n"
 
-	// apply horizontal scroll based on terminal width and offset
-	return table.ApplyHorizontalScroll(s, m.horizOffset, m.width)
+	return s
 }
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/ui/table"
+)
+
+type searchHitsMsg struct {
+	text string
+	hits []db.SearchHit
+	err  error
+}
+
+func searchTablesCmd(client db.DB, text string) tea.Cmd {
+	return func() tea.Msg {
+		hits, err := client.SearchTables(context.Background(), text)
+		return searchHitsMsg{text: text, hits: hits, err: err}
+	}
+}
+
+// ----- Search -----
+
+// searchExpr matches the search text in every searchable column of the
+// table; nil when there is no search.
+func (m Model) searchExpr() db.Expr {
+	if m.search == "" {
+		return nil
+	}
+	s := db.Search{Text: m.search}
+	for _, c := range m.allColumns {
+		if db.Searchable(c) {
+			s.Columns = append(s.Columns, c.Name)
+		}
+	}
+	return s
+}
+
+// whereExpr is the structured part of the query: the built filter and the
+// search, both of which must match.
+func (m Model) whereExpr() db.Expr {
+	filter, search := m.filterWhere, m.searchExpr()
+	switch {
+	case filter == nil:
+		return search
+	case search == nil:
+		return filter
+	}
+	return db.Group{Items: []db.Expr{filter, search}}
+}
+
+// openSearch opens the search prompt; all searches every table instead of
+// the selected one.
+func (m Model) openSearch(all bool) (tea.Model, tea.Cmd) {
+	m.searching = true
+	m.searchAll = all
+	m.editingFilter = false
+	m.searchInput.SetValue(m.search)
+	m.searchInput.CursorEnd()
+	m.searchInput.Focus()
+	m.status = m.searchStatus()
+	return m, nil
+}
+
+func (m Model) searchLabel() string {
+	if m.searchAll {
+		return "Search every table"
+	}
+	return "Search " + m.selectedTable
+}
+
+func (m Model) searchStatus() string {
+	if m.searchAll {
+		return "Type the text to look for in every table. Enter to search, Esc to cancel."
+	}
+	return "Type the text to look for in every column. Enter to search, ctrl+a to search every table instead, Esc to clear."
+}
+
+func (m Model) updateSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.searching = false
+		if m.searchAll || m.search == "" {
+			m.status = "Search cancelled."
+			return m, nil
+		}
+		m.search = ""
+		m.offset = 0
+		m.clearMarks()
+		m.loading = true
+		m.status = "Search cleared."
+		return m, fetchRowsCmd(m.dbClient, m.selectedTable, m.queryOptions(m.offset))
+
+	case "ctrl+a":
+		if m.mode == modeRows {
+			m.searchAll = !m.searchAll
+			m.status = m.searchStatus()
+		}
+		return m, nil
+
+	case "enter":
+		text := m.searchInput.Value()
+		m.searching = false
+		if m.searchAll {
+			if text == "" {
+				m.status = "Type something to search for."
+				return m, nil
+			}
+			m.searchFrom = m.mode
+			m.loading = true
+			m.status = "Searching every table for " + db.QuoteLiteral(text) + "..."
+			return m, searchTablesCmd(m.dbClient, text)
+		}
+		m.search = text
+		m.offset = 0
+		m.rowCursor = 0
+		m.clearMarks()
+		m.loading = true
+		m.status = "Searching..."
+		return m, fetchRowsCmd(m.dbClient, m.selectedTable, m.queryOptions(m.offset))
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	return m, cmd
+}
+
+// ----- Hits across tables -----
+
+func (m Model) searchDone(msg searchHitsMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.status = "Search failed: " + msg.err.Error()
+		return m, nil
+	}
+	m.searchText = msg.text
+	m.searchHits = msg.hits
+	m.searchCursor = 0
+	m.mode = modeSearch
+
+	tables := map[string]bool{}
+	for _, h := range msg.hits {
+		tables[h.Table] = true
+	}
+	m.status = fmt.Sprintf("Found %s in %d column(s) of %d table(s).", db.QuoteLiteral(msg.text), len(msg.hits), len(tables))
+	return m, nil
+}
+
+func (m Model) updateSearchHitsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.quit()
+	case "esc", "b", "q":
+		m.mode = m.searchFrom
+		m.status = "Back."
+		return m, nil
+	case "up", "k":
+		if m.searchCursor > 0 {
+			m.searchCursor--
+		}
+	case "down", "j":
+		if m.searchCursor < len(m.searchHits)-1 {
+			m.searchCursor++
+		}
+	case "enter":
+		if len(m.searchHits) == 0 {
+			return m, nil
+		}
+		hit := m.searchHits[m.searchCursor]
+		for i, t := range m.tableNames {
+			if t == hit.Table {
+				m.tableCursor = i
+			}
+		}
+		next, cmd := m.openTable(hit.Table)
+		m = next.(Model)
+		m.search = m.searchText
+		m.status = "Fetching rows of " + hit.Table + " matching " + db.QuoteLiteral(m.searchText) + "..."
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) viewSearchHits() string {
+	s := m.theme.Title.Render("Tables containing "+db.QuoteLiteral(m.searchText)) + "\n\n"
+
+	if len(m.searchHits) == 0 {
+		s += "  (no matches in any table)\n"
+	}
+
+	tableWidth, columnWidth := 0, 0
+	for _, h := range m.searchHits {
+		tableWidth = max(tableWidth, table.Width(h.Table))
+		columnWidth = max(columnWidth, table.Width(h.Column))
+	}
+	for i, h := range m.searchHits {
+		cursor := "  "
+		if i == m.searchCursor {
+			cursor = "> "
+		}
+		name := h.Table + strings.Repeat(" ", tableWidth-table.Width(h.Table))
+		column := h.Column + strings.Repeat(" ", columnWidth-table.Width(h.Column))
+		line := fmt.Sprintf("%s  %s  %d row(s)", name, column, h.Rows)
+		if i == m.searchCursor {
+			line = m.theme.Selected.Render(line)
+		}
+		s += cursor + line + "\n"
+	}
+
+	s += "\n" + m.theme.Status.Render(m.status) + "\n"
+	s += "\n" + m.theme.Help.Render("↑/↓ to move, Enter opens the table with the search applied, Esc to go back.") + "\n"
+	return s
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/mattn/go-runewidth"
+)
+
+// ----- Commands -----
+
+func deleteKeysCmd(client db.DB, tableName string, keys []db.RowKey) tea.Cmd {
+	return func() tea.Msg {
+		images, err := client.DeleteByKeys(context.Background(), tableName, keys)
+		return deleteResultMsg{images: images, err: err}
+	}
+}
+
+// ----- Marked rows -----
+
+// toggleMark marks or unmarks the row under the cursor. Marks are keyed by
+// the row's position in the filtered result, so they survive paging.
+func (m *Model) toggleMark() {
+	if m.rowCursor >= len(m.rows) {
+		return
+	}
+	if m.marked == nil {
+		m.marked = make(map[int][]db.Value)
+	}
+	idx := m.offset + m.rowCursor
+	if _, ok := m.marked[idx]; ok {
+		delete(m.marked, idx)
+	} else {
+		m.marked[idx] = m.rows[m.rowCursor]
+	}
+	m.status = fmt.Sprintf("%d row(s) marked.", len(m.marked))
+}
+
+// clearMarks drops the selection; used whenever the result it points into
+// changes (table, filter or projection).
+func (m *Model) clearMarks() {
+	m.marked = nil
+	m.visual = false
+}
+
+// toggleVisual starts a range selection at the cursor, or marks the range
+// between its start and the cursor when one is active.
+func (m *Model) toggleVisual() {
+	if m.rowCursor >= len(m.rows) {
+		return
+	}
+	if !m.visual {
+		m.visual = true
+		m.visualFrom = m.offset + m.rowCursor
+		m.status = "Visual: move with ↑/↓, 'v' or Space to mark the range, Esc to cancel."
+		return
+	}
+
+	if m.marked == nil {
+		m.marked = make(map[int][]db.Value)
+	}
+	from, to := m.visualRange()
+	for i := from; i <= to; i++ {
+		m.marked[i] = m.rows[i-m.offset]
+	}
+	m.visual = false
+	m.status = fmt.Sprintf("%d row(s) marked.", len(m.marked))
+}
+
+// visualRange returns the selected rows as positions in the result; the
+// range never leaves the current page.
+func (m Model) visualRange() (int, int) {
+	from, to := m.visualFrom, m.offset+m.rowCursor
+	if from > to {
+		from, to = to, from
+	}
+	return max(from, m.offset), min(to, m.offset+len(m.rows)-1)
+}
+
+// markedRows returns the marked rows in result order.
+func (m Model) markedRows() [][]db.Value {
+	idx := make([]int, 0, len(m.marked))
+	for i := range m.marked {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+
+	rows := make([][]db.Value, len(idx))
+	for i, n := range idx {
+		rows[i] = m.marked[n]
+	}
+	return rows
+}
+
+// pageMarks reports which rows of the current page are marked or inside
+// the visual range.
+func (m Model) pageMarks() []bool {
+	marks := make([]bool, len(m.rows))
+	for i := range m.rows {
+		_, marks[i] = m.marked[m.offset+i]
+	}
+	if m.visual {
+		from, to := m.visualRange()
+		for i := from; i <= to; i++ {
+			marks[i-m.offset] = true
+		}
+	}
+	return marks
+}
+
+// ----- Deleting marked rows -----
+
+func (m Model) confirmDeleteMarked() (tea.Model, tea.Cmd) {
+	if len(m.marked) == 0 {
+		m.status = "Mark rows with Space or 'v' first."
+		return m, nil
+	}
+
+	rows := m.markedRows()
+	keys := make([]db.RowKey, len(rows))
+	for i, row := range rows {
+		key, err := m.keyOf(row)
+		if err != nil {
+			m.status = "Cannot delete: " + err.Error()
+			return m, nil
+		}
+		keys[i] = key
+	}
+
+	m.pendingDelete = keys
+	sql := runewidth.Truncate(db.DeleteKeysSQL(m.selectedTable, m.keyColumns, len(keys)), 120, "…")
+	m.status = fmt.Sprintf("Delete %d marked row(s) with %s? (y/n)", len(keys), sql)
+	return m, nil
+}
+
+func (m Model) updateDeleteMarkedKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := m.pendingDelete
+	m.pendingDelete = nil
+	if msg.String() != "y" {
+		m.status = "Delete cancelled."
+		return m, nil
+	}
+	m.loading = true
+	m.status = fmt.Sprintf("Deleting %d row(s)...", len(keys))
+	return m.guardWrite(fmt.Sprintf("delete %d marked row(s) from %s", len(keys), m.selectedTable), func() tea.Cmd {
+		return deleteKeysCmd(m.dbClient, m.selectedTable, keys)
+	})
+}
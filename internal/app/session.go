@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// ----- Transaction session -----
+
+type txAction int
+
+const (
+	txBegin txAction = iota
+	txCommit
+	txRollback
+)
+
+type txResultMsg struct {
+	action txAction
+	err    error
+}
+
+func txCmd(client db.DB, action txAction) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		var err error
+		switch action {
+		case txBegin:
+			err = client.Begin(ctx)
+		case txCommit:
+			err = client.Commit(ctx)
+		case txRollback:
+			err = client.Rollback(ctx)
+		}
+		return txResultMsg{action: action, err: err}
+	}
+}
+
+// txPrompt is a y/n question about the open transaction.
+type txPrompt int
+
+const (
+	txPromptNone txPrompt = iota
+	txPromptCommit
+	txPromptRollback
+	txPromptQuit
+)
+
+// maxChangesShown bounds the pending-changes panel; older entries are
+// summarized in one line.
+const maxChangesShown = 8
+
+// recordChange adds a write to the pending-changes panel when it ran
+// inside the transaction.
+func (m *Model) recordChange(format string, args ...any) {
+	if m.txOpen {
+		m.txChanges = append(m.txChanges, fmt.Sprintf(format, args...))
+	}
+}
+
+// updateTxKey handles the transaction keys shared by the tables and rows
+// screens; ok is false for any other key.
+func (m Model) updateTxKey(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	switch msg.String() {
+	case "T":
+		if m.txOpen {
+			m.status = "A transaction is already open: 'C' to commit, 'R' to roll back."
+			return m, nil, true
+		}
+		if !m.allowWrite() {
+			return m, nil, true
+		}
+		m.loading = true
+		m.status = "Opening transaction..."
+		return m, txCmd(m.dbClient, txBegin), true
+
+	case "C", "R":
+		if !m.txOpen {
+			m.status = "No transaction is open. Press 'T' to begin one."
+			return m, nil, true
+		}
+		if msg.String() == "C" {
+			m.txPrompt = txPromptCommit
+			m.status = fmt.Sprintf("Commit %d change(s)? (y/n)", len(m.txChanges))
+		} else {
+			m.txPrompt = txPromptRollback
+			m.status = fmt.Sprintf("Roll back %d change(s)? (y/n)", len(m.txChanges))
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+func (m Model) updateTxPromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	prompt := m.txPrompt
+	switch msg.String() {
+	case "y":
+		m.txPrompt = txPromptNone
+		switch prompt {
+		case txPromptCommit:
+			m.loading = true
+			m.status = "Committing..."
+			return m.guardWrite(fmt.Sprintf("commit %d change(s)", len(m.txChanges)), func() tea.Cmd {
+				return txCmd(m.dbClient, txCommit)
+			})
+		case txPromptRollback:
+			m.loading = true
+			m.status = "Rolling back..."
+			return m, txCmd(m.dbClient, txRollback)
+		case txPromptQuit:
+			// closing the connection rolls the transaction back
+			return m, tea.Quit
+		}
+
+	case "n", "esc", "ctrl+c":
+		m.txPrompt = txPromptNone
+		m.status = "Transaction still open."
+	}
+	return m, nil
+}
+
+// quit exits, asking first if a transaction is still open.
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	if !m.txOpen {
+		return m, tea.Quit
+	}
+	m.txPrompt = txPromptQuit
+	m.status = fmt.Sprintf("A transaction with %d change(s) is still open and will be rolled back. Quit anyway? (y/n)", len(m.txChanges))
+	return m, nil
+}
+
+func (m Model) txDone(msg txResultMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	err := m.logged(msg.err)
+	switch msg.action {
+	case txBegin:
+		if err != nil {
+			m.status = "Could not open a transaction: " + err.Error()
+			return m, nil
+		}
+		m.txOpen = true
+		m.txChanges = nil
+		m.txJournal, m.txUndone = len(m.journal), nil
+		m.status = "Transaction open. Changes stay pending until 'C' commits or 'R' rolls them back."
+		return m, nil
+
+	case txCommit:
+		// a failed COMMIT still ends the transaction
+		m.txOpen = false
+		if err != nil {
+			m.dropTxJournal()
+			m.status = "Commit failed, all changes were rolled back: " + err.Error()
+		} else {
+			m.status = fmt.Sprintf("Committed %d change(s).", len(m.txChanges))
+		}
+
+	case txRollback:
+		m.txOpen = false
+		// the transaction is gone either way
+		m.dropTxJournal()
+		if err != nil {
+			m.status = "Rollback failed: " + err.Error()
+		} else {
+			m.status = fmt.Sprintf("Rolled back %d change(s).", len(m.txChanges))
+		}
+	}
+	m.txChanges = nil
+
+	// show the table as it is now
+	if m.mode == modeRows {
+		m.clearMarks()
+		m.loading = true
+		return m, fetchRowsCmd(m.dbClient, m.selectedTable, m.queryOptions(m.offset))
+	}
+	return m, nil
+}
+
+// viewTx lists the pending changes of the open transaction.
+func (m Model) viewTx() string {
+	if !m.txOpen {
+		return ""
+	}
+
+	s := "\n" + m.theme.Title.Render(fmt.Sprintf("Transaction: %d pending change(s)", len(m.txChanges))) + "\n"
+	changes := m.txChanges
+	if n := len(changes) - maxChangesShown; n > 0 {
+		s += fmt.Sprintf("  ... %d earlier\n", n)
+		changes = changes[n:]
+	}
+	for _, c := range changes {
+		s += "  " + c + "\n"
+	}
+	if len(m.txChanges) == 0 {
+		s += "  (none yet)\n"
+	}
+	s += m.theme.Help.Render("'C' commits, 'R' rolls back.") + "\n"
+	return s
+}
+
+// plural formats a row count for the pending-changes panel.
+func plural(n int64) string {
+	if n == 1 {
+		return "1 row"
+	}
+	return fmt.Sprintf("%d rows", n)
+}
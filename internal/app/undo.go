@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/mattn/go-runewidth"
+)
+
+// ----- Undo journal -----
+
+// journalEntry is a write that can be undone: deleted rows are inserted
+// back, an updated column is set back to its old value.
+type journalEntry struct {
+	id     int
+	table  string
+	what   string // e.g. "DELETE FROM users: 3 rows"
+	images []db.RowImage
+
+	columns []string // delete: columns to insert back
+	key     []string // update: columns to find the rows by
+	column  string   // update: the column that was set
+	value   any      // update: what it was set to
+}
+
+// sql is the compensating statement for the entry.
+func (e journalEntry) sql() string {
+	if e.column != "" {
+		return db.RevertSQL(e.table, e.key, e.column)
+	}
+	return db.RestoreSQL(e.table, e.columns)
+}
+
+type undoResultMsg struct {
+	entry    journalEntry
+	affected int64
+	err      error
+}
+
+func undoCmd(client db.DB, e journalEntry) tea.Cmd {
+	return func() tea.Msg {
+		var (
+			n   int64
+			err error
+		)
+		if e.column != "" {
+			n, err = client.RevertColumn(context.Background(), e.table, e.key, e.column, e.value, e.images)
+		} else {
+			n, err = client.RestoreRows(context.Background(), e.table, e.columns, e.images)
+		}
+		return undoResultMsg{entry: e, affected: n, err: err}
+	}
+}
+
+// journalDelete records rows deleted from the selected table. Generated
+// columns are left for the database to compute again.
+func (m *Model) journalDelete(images []db.RowImage) {
+	if len(images) == 0 {
+		return
+	}
+	var columns []string
+	for _, c := range m.allColumns {
+		if !c.Generated {
+			columns = append(columns, c.Name)
+		}
+	}
+	m.journalSeq++
+	m.journal = append(m.journal, journalEntry{
+		id:      m.journalSeq,
+		table:   m.selectedTable,
+		what:    fmt.Sprintf("DELETE FROM %s: %s", m.selectedTable, plural(int64(len(images)))),
+		images:  images,
+		columns: columns,
+	})
+}
+
+// journalUpdate records a column set to value in the selected table.
+func (m *Model) journalUpdate(column string, value any, images []db.RowImage) {
+	m.journalSeq++
+	m.journal = append(m.journal, journalEntry{
+		id:     m.journalSeq,
+		table:  m.selectedTable,
+		what:   fmt.Sprintf("UPDATE %s SET %s: %s", m.selectedTable, column, plural(int64(len(images)))),
+		images: images,
+		key:    m.keyColumns,
+		column: column,
+		value:  value,
+	})
+}
+
+// confirmUndo asks before undoing the newest entry of the journal.
+func (m Model) confirmUndo() (tea.Model, tea.Cmd) {
+	if len(m.journal) == 0 {
+		m.status = "Nothing to undo."
+		return m, nil
+	}
+	e := m.journal[len(m.journal)-1]
+	m.undoPending = true
+	sql := runewidth.Truncate(e.sql(), 120, "…")
+	m.status = fmt.Sprintf("Undo %s with %s? (y/n)", e.what, sql)
+	return m, nil
+}
+
+func (m Model) updateUndoKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.undoPending = false
+	if msg.String() != "y" || len(m.journal) == 0 {
+		m.status = "Undo cancelled."
+		return m, nil
+	}
+	e := m.journal[len(m.journal)-1]
+	m.loading = true
+	m.status = "Undoing " + e.what + "..."
+	return m.guardWrite("undo "+e.what, func() tea.Cmd {
+		return undoCmd(m.dbClient, e)
+	})
+}
+
+func (m Model) undoDone(msg undoResultMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if err := m.logged(msg.err); err != nil {
+		// keep the entry: the cause may be fixed and the undo tried again
+		m.status = "Undo failed: " + err.Error()
+		return m, nil
+	}
+
+	// the entry undone is the newest unless a write finished in between
+	for i := len(m.journal) - 1; i >= 0; i-- {
+		if m.journal[i].id == msg.entry.id {
+			m.journal = append(m.journal[:i], m.journal[i+1:]...)
+			break
+		}
+	}
+	if m.txOpen {
+		m.txUndone = append(m.txUndone, msg.entry)
+	}
+	m.recordChange("undo %s", msg.entry.what)
+	m.status = fmt.Sprintf("Undid %s (%s).", msg.entry.what, plural(msg.affected))
+	if len(m.journal) > 0 {
+		m.status += fmt.Sprintf(" %d more to undo.", len(m.journal))
+	}
+
+	if m.mode != modeRows {
+		return m, nil
+	}
+	m.clearMarks()
+	m.loading = true
+	return m, fetchRowsCmd(m.dbClient, m.selectedTable, m.queryOptions(m.offset))
+}
+
+// dropTxJournal forgets the entries of a rolled-back transaction and
+// brings back the ones it undid.
+func (m *Model) dropTxJournal() {
+	if m.txJournal < len(m.journal) {
+		m.journal = m.journal[:m.txJournal]
+	}
+	for i := len(m.txUndone) - 1; i >= 0; i-- {
+		m.journal = append(m.journal, m.txUndone[i])
+	}
+}
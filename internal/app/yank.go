@@ -0,0 +1,117 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/export"
+)
+
+// ----- Messages -----
+
+type yankDoneMsg struct {
+	what string
+}
+
+// ----- Commands -----
+
+// copyCmd puts text on the clipboard. The OSC 52 escape asks the terminal
+// to do it, which also works over SSH and inside tmux/screen. It is printed
+// through the program, which writes it between frames rather than in the
+// middle of one. On a local session the system clipboard is set as well,
+// for terminals without OSC 52.
+func copyCmd(text, what string) tea.Cmd {
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+
+	return tea.Sequence(
+		tea.Printf("%s", seq),
+		func() tea.Msg {
+			if os.Getenv("SSH_TTY") == "" && !clipboard.Unsupported {
+				// best effort: the terminal may have taken the copy
+				_ = clipboard.WriteAll(text)
+			}
+			return yankDoneMsg{what: what}
+		},
+	)
+}
+
+// ----- Yank keys -----
+
+// startYank waits for the second key of a y-sequence.
+func (m Model) startYank() (tea.Model, tea.Cmd) {
+	if len(m.columns) == 0 {
+		return m, nil
+	}
+	m.yankPending = true
+	m.status = "Yank: 'y'/'c' cell, 'r' row as TSV, 'j' row as JSON, 'i' row as INSERT, 'p' page as TSV. Esc to cancel."
+	return m, nil
+}
+
+func (m Model) updateYankKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.yankPending = false
+
+	var (
+		text, what string
+		err        error
+	)
+	row := [][]db.Value(nil)
+	if m.rowCursor < len(m.rows) {
+		row = [][]db.Value{m.rows[m.rowCursor]}
+	}
+
+	switch msg.String() {
+	case "y", "c":
+		value, ok := m.cellAt()
+		if !ok {
+			return m, nil
+		}
+		// NULL copies as empty text
+		if !value.Null {
+			text = export.Text(value)
+		}
+		what = "cell " + m.columns[m.colCursor].Name
+	case "r":
+		if row == nil {
+			return m, nil
+		}
+		text, err = export.String(export.TSV, export.Options{}, m.columns, row)
+		// drop the header line; escaped TSV fields never contain a newline
+		_, text, _ = strings.Cut(text, "\n")
+		what = "row as TSV"
+	case "j":
+		if row == nil {
+			return m, nil
+		}
+		text = export.Object(m.columns, row[0])
+		what = "row as JSON"
+	case "i":
+		if row == nil {
+			return m, nil
+		}
+		text, err = export.String(export.SQL, m.exportOptions(), m.columns, row)
+		what = "row as INSERT"
+	case "p":
+		text, err = export.String(export.TSV, export.Options{}, m.columns, m.rows)
+		what = fmt.Sprintf("page (%d rows) as TSV", len(m.rows))
+	default:
+		m.status = "Yank cancelled."
+		return m, nil
+	}
+
+	if err != nil {
+		m.status = "Copy failed: " + err.Error()
+		return m, nil
+	}
+	return m, copyCmd(text, what)
+}
@@ -0,0 +1,125 @@
+// Package audit keeps an append-only log, one JSON object per line, of
+// every statement dbls runs that changes data.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is one line of the log.
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Profile  string    `json:"profile,omitempty"`
+	Database string    `json:"database"`
+	User     string    `json:"user"`
+	SQL      string    `json:"sql"`
+	Params   []any     `json:"params,omitempty"`
+	Affected int64     `json:"affected"`
+	Error    string    `json:"error,omitempty"` // the statement failed
+}
+
+// Log appends entries to a file. It is safe for concurrent use.
+type Log struct {
+	mu   sync.Mutex
+	f    *os.File
+	path string
+}
+
+// New returns a log that appends to the file at path. The file and its
+// directory are created on the first write, so a session that only reads
+// never touches it; "" is a log with no file, where every write fails.
+func New(path string) *Log {
+	return &Log{path: path}
+}
+
+// open opens the file for appending if it isn't yet. l.mu must be held.
+func (l *Log) open() error {
+	if l.f != nil {
+		return nil
+	}
+	if l.path == "" {
+		return errors.New("no file for the audit log, pass --audit-log")
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	l.f = f
+	return nil
+}
+
+// Path returns the file the log is written to.
+func (l *Log) Path() string { return l.path }
+
+// Write appends e as one line. Each entry is a single write call, so lines
+// from several processes sharing the file don't interleave.
+func (l *Log) Write(e Entry) error {
+	// keep <, > and & readable in the SQL
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(e); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.open(); err != nil {
+		return err
+	}
+	_, err := l.f.Write(buf.Bytes())
+	return err
+}
+
+// Close closes the file if it was opened.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+// Read returns the last n entries of the log at path, oldest first. A
+// missing file has no entries.
+func Read(path string, n int) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	sc := bufio.NewScanner(f)
+	// images of large deletes make long lines
+	sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+		entries = append(entries, e)
+		if len(entries) > n {
+			entries = entries[1:]
+		}
+	}
+	return entries, sc.Err()
+}
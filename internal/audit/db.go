@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// Wrap returns a db.DB that runs every call on inner and logs each one
+// that changes data, along with the profile name and the user and
+// database it connected as. Reads pass straight through.
+func Wrap(inner db.DB, log *Log, profile string) db.DB {
+	return &auditedDB{DB: inner, log: log, profile: profile}
+}
+
+type auditedDB struct {
+	db.DB
+	log     *Log
+	profile string
+
+	user, database string
+}
+
+func (a *auditedDB) Connect(ctx context.Context, cfg db.ConnConfig) error {
+	a.user, a.database = cfg.User, cfg.Database
+	return a.DB.Connect(ctx, cfg)
+}
+
+// Close rolls back an open transaction through the log before closing, so
+// the log shows it was not committed.
+func (a *auditedDB) Close() error {
+	if a.DB.InTransaction() {
+		a.Rollback(context.Background())
+	}
+	return a.DB.Close()
+}
+
+// LogError is returned when a statement succeeded but could not be written
+// to the audit log. The statement's results stand.
+type LogError struct {
+	Err error
+}
+
+func (e *LogError) Error() string {
+	return "the statement ran but could not be written to the audit log: " + e.Err.Error()
+}
+
+func (e *LogError) Unwrap() error { return e.Err }
+
+// record logs one statement. The write has already happened, so a log
+// failure is reported on top of its outcome rather than instead of it.
+func (a *auditedDB) record(sql string, params []any, affected int64, err error) error {
+	e := Entry{
+		Time:     time.Now(),
+		Profile:  a.profile,
+		Database: a.database,
+		User:     a.user,
+		SQL:      sql,
+		Affected: affected,
+	}
+	for _, p := range params {
+		e.Params = append(e.Params, param(p))
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	if logErr := a.log.Write(e); logErr != nil {
+		if err != nil {
+			return fmt.Errorf("%w (and the audit log failed: %v)", err, logErr)
+		}
+		return &LogError{Err: logErr}
+	}
+	return err
+}
+
+func (a *auditedDB) DeleteRows(ctx context.Context, table string, where string, expect int64) ([]db.RowImage, error) {
+	images, err := a.DB.DeleteRows(ctx, table, where, expect)
+	return images, a.record(db.DeleteSQL(table, where), nil, int64(len(images)), err)
+}
+
+func (a *auditedDB) CopyRows(ctx context.Context, table string, columns []string, src db.RowSource) (int64, error) {
+	n, err := a.DB.CopyRows(ctx, table, columns, src)
+	return n, a.record(db.CopySQL(table, columns), nil, n, err)
+}
+
+func (a *auditedDB) UpdateRow(ctx context.Context, table string, key db.RowKey, column string, value any) ([]db.RowImage, error) {
+	images, err := a.DB.UpdateRow(ctx, table, key, column, value)
+	params := append([]any{value}, key.Values...)
+	return images, a.record(db.UpdateSQL(table, key, column), params, int64(len(images)), err)
+}
+
+func (a *auditedDB) DeleteByKeys(ctx context.Context, table string, keys []db.RowKey) ([]db.RowImage, error) {
+	images, err := a.DB.DeleteByKeys(ctx, table, keys)
+	if len(keys) == 0 {
+		return images, err
+	}
+	var params []any
+	for _, k := range keys {
+		params = append(params, k.Values...)
+	}
+	return images, a.record(db.DeleteKeysSQL(table, keys[0].Columns, len(keys)), params, int64(len(images)), err)
+}
+
+func (a *auditedDB) InsertRow(ctx context.Context, table string, columns []string, values []any) (db.RowPage, error) {
+	page, err := a.DB.InsertRow(ctx, table, columns, values)
+	return page, a.record(db.InsertSQL(table, columns), values, int64(len(page.Rows)), err)
+}
+
+func (a *auditedDB) RestoreRows(ctx context.Context, table string, columns []string, images []db.RowImage) (int64, error) {
+	n, err := a.DB.RestoreRows(ctx, table, columns, images)
+	return n, a.record(db.RestoreSQL(table, columns), []any{db.ImageArray(images)}, n, err)
+}
+
+func (a *auditedDB) RevertColumn(ctx context.Context, table string, key []string, column string, value any, images []db.RowImage) (int64, error) {
+	n, err := a.DB.RevertColumn(ctx, table, key, column, value, images)
+	return n, a.record(db.RevertSQL(table, key, column), db.RevertArgs(key, column, value, images), n, err)
+}
+
+// Transactions are logged too: writes made in one only count if a COMMIT
+// follows them.
+
+func (a *auditedDB) Begin(ctx context.Context) error {
+	return a.record("BEGIN", nil, 0, a.DB.Begin(ctx))
+}
+
+func (a *auditedDB) Commit(ctx context.Context) error {
+	return a.record("COMMIT", nil, 0, a.DB.Commit(ctx))
+}
+
+func (a *auditedDB) Rollback(ctx context.Context) error {
+	return a.record("ROLLBACK", nil, 0, a.DB.Rollback(ctx))
+}
+
+// param converts a bound value to something that reads well as JSON.
+func param(v any) any {
+	switch v := v.(type) {
+	case nil, string, bool, int, int16, int32, int64, float32, float64:
+		return v
+	case []byte:
+		return `\x` + hex.EncodeToString(v)
+	case [16]byte: // uuid
+		h := hex.EncodeToString(v[:])
+		return strings.Join([]string{h[:8], h[8:12], h[12:16], h[16:20], h[20:]}, "-")
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case driver.Valuer:
+		if dv, err := v.Value(); err == nil {
+			return param(dv)
+		}
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,61 @@
+package config
+
+// Layout is the user's column arrangement for one table.
+type Layout struct {
+	Visible []string `json:"visible"`          // shown columns, in order
+	Hidden  []string `json:"hidden,omitempty"` // columns that are not fetched
+}
+
+// Layouts maps LayoutKey(database, table) to a layout.
+type Layouts map[string]Layout
+
+const layoutsFile = "layouts.json"
+
+// LayoutKey identifies a table across sessions.
+func LayoutKey(database, table string) string {
+	return database + "/" + table
+}
+
+// LoadLayouts reads saved layouts; a missing file yields an empty set.
+func LoadLayouts() (Layouts, error) {
+	layouts := make(Layouts)
+	if err := load(layoutsFile, &layouts); err != nil {
+		return make(Layouts), err
+	}
+	return layouts, nil
+}
+
+// Save writes all layouts back to disk.
+func (l Layouts) Save() error {
+	return save(layoutsFile, l)
+}
+
+// Apply orders the table's columns by the layout. Columns the layout
+// doesn't know about (added since it was saved) are appended as visible;
+// names that no longer exist are dropped.
+func (l Layout) Apply(columns []string) (visible, hidden []string) {
+	exists := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		exists[c] = true
+	}
+	seen := make(map[string]bool, len(columns))
+
+	for _, c := range l.Visible {
+		if exists[c] && !seen[c] {
+			visible = append(visible, c)
+			seen[c] = true
+		}
+	}
+	for _, c := range l.Hidden {
+		if exists[c] && !seen[c] {
+			hidden = append(hidden, c)
+			seen[c] = true
+		}
+	}
+	for _, c := range columns {
+		if !seen[c] {
+			visible = append(visible, c)
+		}
+	}
+	return visible, hidden
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Settings are user preferences read from config.json.
+type Settings struct {
+	Theme    string             `json:"theme,omitempty"` // dark, light, high-contrast, no-color
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+}
+
+// Profile is a saved connection, picked with --profile. The password is
+// never stored; it is typed into the form.
+type Profile struct {
+	Host     string `json:"host,omitempty"`
+	Port     string `json:"port,omitempty"`
+	User     string `json:"user,omitempty"`
+	Database string `json:"database,omitempty"`
+
+	ReadOnly   bool `json:"readonly,omitempty"`   // connect with default_transaction_read_only
+	Production bool `json:"production,omitempty"` // banner and extra confirmation for writes
+}
+
+const settingsFile = "config.json"
+
+// LoadSettings reads config.json; a missing file yields zero settings.
+func LoadSettings() (Settings, error) {
+	var s Settings
+	err := load(settingsFile, &s)
+	return s, err
+}
+
+// Profile returns the named profile.
+func (s Settings) Profile(name string) (Profile, error) {
+	p, ok := s.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(s.Profiles))
+		for n := range s.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Profile{}, fmt.Errorf("unknown profile %q (configured: %v)", name, names)
+	}
+	return p, nil
+}
@@ -0,0 +1,142 @@
+// Package csvimport reads CSV files for loading into a table.
+package csvimport
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// Preview reads the header and up to n data rows of a file.
+func Preview(path string, n int) ([]string, [][]Field, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	r := NewReader(f)
+	head, _, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%s is empty", path)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	header := make([]string, len(head))
+	for i, h := range head {
+		header[i] = h.Text
+	}
+
+	var rows [][]Field
+	for len(rows) < n {
+		rec, _, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, rec)
+	}
+	return header, rows, nil
+}
+
+// Match maps every table column to the CSV field with the same name,
+// ignoring case and surrounding spaces; -1 when there is none.
+func Match(header []string, columns []db.Column) []int {
+	mapping := make([]int, len(columns))
+	for i, c := range columns {
+		mapping[i] = -1
+		for j, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), c.Name) {
+				mapping[i] = j
+				break
+			}
+		}
+	}
+	return mapping
+}
+
+// Value converts a field to what db.CopyRows expects: nil for NULL,
+// otherwise the text.
+func Value(f Field) any {
+	if f.Text == "" && !f.Quoted {
+		return nil
+	}
+	return f.Text
+}
+
+// Source streams the data rows of a file as a db.RowSource, picking the
+// fields given by index in the order of the target columns.
+type Source struct {
+	file     *os.File
+	r        *Reader
+	fields   []int
+	values   []any
+	err      error
+	lines    []int // CSV line of every row handed out
+	progress func(rows int)
+}
+
+// Open starts reading after the header. progress, if set, is called every
+// 1000 rows.
+func Open(path string, fields []int, progress func(rows int)) (*Source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Source{file: f, r: NewReader(f), fields: fields, progress: progress}
+	if _, _, err := s.r.Read(); err != nil && err != io.EOF {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Source) Next() bool {
+	rec, line, err := s.r.Read()
+	if err == io.EOF {
+		return false
+	}
+	if err != nil {
+		s.err = err
+		return false
+	}
+	s.lines = append(s.lines, line)
+
+	s.values = make([]any, len(s.fields))
+	for i, idx := range s.fields {
+		if idx >= len(rec) {
+			s.err = &ParseError{Line: line, Err: fmt.Errorf("expected at least %d fields, found %d", idx+1, len(rec))}
+			return false
+		}
+		s.values[i] = Value(rec[idx])
+	}
+
+	if s.progress != nil && len(s.lines)%1000 == 0 {
+		s.progress(len(s.lines))
+	}
+	return true
+}
+
+func (s *Source) Values() ([]any, error) { return s.values, nil }
+
+func (s *Source) Err() error { return s.err }
+
+// Line returns the CSV line of a 1-based row; row 0 means the last row
+// read, which is where a client-side failure happened.
+func (s *Source) Line(row int) int {
+	if row <= 0 || row > len(s.lines) {
+		row = len(s.lines)
+	}
+	if row == 0 {
+		return 0
+	}
+	return s.lines[row-1]
+}
+
+func (s *Source) Close() error { return s.file.Close() }
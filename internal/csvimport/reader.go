@@ -0,0 +1,158 @@
+package csvimport
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Field is one CSV field. Quoted tells the empty string ("") apart from an
+// empty unquoted field, which loads as NULL like COPY ... CSV does.
+type Field struct {
+	Text   string
+	Quoted bool
+}
+
+// Reader parses RFC 4180 CSV, with LF or CRLF line endings, keeping track
+// of the line every record starts on. encoding/csv can't be used because
+// it drops whether a field was quoted.
+type Reader struct {
+	r     *bufio.Reader
+	line  int // current line, 1-based
+	peek  rune
+	held  bool
+	width int // fields in the first record, 0 before it
+}
+
+// ParseError is a malformed record, with the line the problem is on.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string { return fmt.Sprintf("line %d: %v", e.Line, e.Err) }
+func (e *ParseError) Unwrap() error { return e.Err }
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r), line: 1}
+}
+
+func (r *Reader) next() (rune, error) {
+	if r.held {
+		r.held = false
+		return r.peek, nil
+	}
+	c, _, err := r.r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if c == '\n' {
+		r.line++
+	}
+	return c, nil
+}
+
+// unread pushes c back; the line count is unaffected because a held rune
+// was already counted.
+func (r *Reader) unread(c rune) {
+	r.peek, r.held = c, true
+}
+
+// Read returns the next record and the line it starts on, or io.EOF.
+// Blank lines are skipped, except after a first record of one field: in
+// such a file a blank line is a record with an empty field, as for COPY.
+func (r *Reader) Read() ([]Field, int, error) {
+	for {
+		start := r.line
+		fields, err := r.record(start)
+		if err != nil {
+			return nil, start, err
+		}
+		if len(fields) == 1 && fields[0] == (Field{}) && r.width != 1 {
+			continue
+		}
+		if r.width == 0 {
+			r.width = len(fields)
+		}
+		return fields, start, nil
+	}
+}
+
+func (r *Reader) record(start int) ([]Field, error) {
+	var (
+		fields []Field
+		sb     strings.Builder
+		quoted bool
+	)
+	first := true
+	for {
+		c, err := r.next()
+		if err == io.EOF {
+			if first && len(fields) == 0 {
+				return nil, io.EOF
+			}
+			return append(fields, Field{Text: sb.String(), Quoted: quoted}), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start == 1 && first && len(fields) == 0 && c == '\uFEFF' {
+			// byte order mark
+			continue
+		}
+
+		switch {
+		case c == '"' && first:
+			quoted = true
+			if err := r.quoted(&sb, start); err != nil {
+				return nil, err
+			}
+		case c == ',':
+			fields = append(fields, Field{Text: sb.String(), Quoted: quoted})
+			sb.Reset()
+			quoted = false
+			first = true
+			continue
+		case c == '\r':
+			if n, err := r.next(); err == nil && n != '\n' {
+				r.unread(n)
+			}
+			return append(fields, Field{Text: sb.String(), Quoted: quoted}), nil
+		case c == '\n':
+			return append(fields, Field{Text: sb.String(), Quoted: quoted}), nil
+		case quoted:
+			return nil, &ParseError{Line: r.line, Err: fmt.Errorf("unexpected %q after closing quote", c)}
+		default:
+			sb.WriteRune(c)
+		}
+		first = false
+	}
+}
+
+// quoted reads a quoted field up to its closing quote.
+func (r *Reader) quoted(sb *strings.Builder, start int) error {
+	for {
+		c, err := r.next()
+		if err == io.EOF {
+			return &ParseError{Line: start, Err: errors.New("quoted field is never closed")}
+		}
+		if err != nil {
+			return err
+		}
+		if c != '"' {
+			sb.WriteRune(c)
+			continue
+		}
+		n, err := r.next()
+		if err == nil && n == '"' {
+			sb.WriteRune('"')
+			continue
+		}
+		if err == nil {
+			r.unread(n)
+		}
+		return nil
+	}
+}
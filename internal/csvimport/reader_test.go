@@ -0,0 +1,104 @@
+package csvimport
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// record is a parsed record with the line it starts on.
+type record struct {
+	line   int
+	fields []Field
+}
+
+func readAll(t *testing.T, in string) ([]record, error) {
+	t.Helper()
+	r := NewReader(strings.NewReader(in))
+	var out []record
+	for {
+		fields, line, err := r.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, record{line, fields})
+	}
+}
+
+func f(s string) Field { return Field{Text: s} }
+func q(s string) Field { return Field{Text: s, Quoted: true} }
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []record
+	}{
+		{"plain", "a,b\n1,2\n", []record{{1, []Field{f("a"), f("b")}}, {2, []Field{f("1"), f("2")}}}},
+		{"CRLF", "a,b\r\n1,2\r\n", []record{{1, []Field{f("a"), f("b")}}, {2, []Field{f("1"), f("2")}}}},
+		{"no final newline", "a,b\n1,2", []record{{1, []Field{f("a"), f("b")}}, {2, []Field{f("1"), f("2")}}}},
+		{"byte order mark", "\uFEFFa,b\n", []record{{1, []Field{f("a"), f("b")}}}},
+		{"empty and NULL", "a,b,c\n,\"\",x\n", []record{{1, []Field{f("a"), f("b"), f("c")}}, {2, []Field{f(""), q(""), f("x")}}}},
+		{"trailing empty field", "a,b\n1,\n", []record{{1, []Field{f("a"), f("b")}}, {2, []Field{f("1"), f("")}}}},
+		{"quoted delimiter and quote", "a,b\n\"x,y\",\"say \"\"hi\"\"\"\n", []record{{1, []Field{f("a"), f("b")}}, {2, []Field{q("x,y"), q(`say "hi"`)}}}},
+		{"quoted newline", "a,b\n\"one\ntwo\",3\n4,5\n", []record{{1, []Field{f("a"), f("b")}}, {2, []Field{q("one\ntwo"), f("3")}}, {4, []Field{f("4"), f("5")}}}},
+		{"quote inside unquoted field", "a\nab\"c\n", []record{{1, []Field{f("a")}}, {2, []Field{f(`ab"c`)}}}},
+		{"multi-byte text", "名前,note\n東京,👍🏽\n", []record{{1, []Field{f("名前"), f("note")}}, {2, []Field{f("東京"), f("👍🏽")}}}},
+		{"blank lines skipped", "\na,b\n\n1,2\n\r\n3,4\n", []record{{2, []Field{f("a"), f("b")}}, {4, []Field{f("1"), f("2")}}, {6, []Field{f("3"), f("4")}}}},
+		{"blank line in one field is NULL", "a\n1\n\n2\n\n", []record{{1, []Field{f("a")}}, {2, []Field{f("1")}}, {3, []Field{f("")}}, {4, []Field{f("2")}}, {5, []Field{f("")}}}},
+		{"one field quoted empty", "a\n\"\"\n", []record{{1, []Field{f("a")}}, {2, []Field{q("")}}}},
+		{"empty file", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readAll(t, tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("read %q\ngot  %v\nwant %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		line int
+	}{
+		{"quote never closed", "a,b\n1,2\n3,\"open\nmore\n", 3},
+		{"text after closing quote", "a,b\n\"x\"y,2\n", 2},
+		{"text after a multi-line quoted field", "a,b\n\"x\ny\"z,2\n", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readAll(t, tt.in)
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("got %v, want a ParseError", err)
+			}
+			if pe.Line != tt.line {
+				t.Errorf("error at line %d, want %d: %v", pe.Line, tt.line, err)
+			}
+		})
+	}
+}
+
+func TestValue(t *testing.T) {
+	if v := Value(f("")); v != nil {
+		t.Errorf("Value of an empty unquoted field = %#v, want nil", v)
+	}
+	if v := Value(q("")); v != "" {
+		t.Errorf("Value of \"\" = %#v, want the empty string", v)
+	}
+	if v := Value(f("x")); v != "x" {
+		t.Errorf("Value of x = %#v, want x", v)
+	}
+}
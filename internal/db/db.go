@@ -9,6 +9,27 @@ type ConnConfig struct {
 	User     string
 	Password string
 	Database string
+	TimeZone string // zone for displaying timestamptz; empty follows the server
+	ReadOnly bool   // every transaction is read-only
+}
+
+// BinaryFormat selects how binary (bytea) values are displayed.
+type BinaryFormat int
+
+const (
+	BinaryHex    BinaryFormat = iota // \x0001ff
+	BinaryEscape                     // printable bytes as-is, others as \ooo
+	BinaryBase64
+)
+
+func (f BinaryFormat) String() string {
+	switch f {
+	case BinaryEscape:
+		return "escape"
+	case BinaryBase64:
+		return "base64"
+	}
+	return "hex"
 }
 
 // Options for fetching rows (pagination + filter).
@@ -16,12 +37,70 @@ type QueryOptions struct {
 	Limit  int
 	Offset int
 	Filter string // raw WHERE fragment, without "WHERE"
+	Where  Expr   // structured filter, ANDed with Filter; values are bound
+
+	Columns []string // columns to select, in order; empty means all
+
+	Binary BinaryFormat // display format for binary columns
+}
+
+// TypeClass groups column types that are displayed the same way.
+type TypeClass int
+
+const (
+	ClassOther TypeClass = iota
+	ClassText
+	ClassNumeric
+	ClassBool
+	ClassTime
+	ClassJSON
+	ClassBinary
+)
+
+// Column metadata for a result set.
+type Column struct {
+	Name     string
+	TypeOID  uint32 // backend-specific type id (Postgres OID)
+	TypeName string // e.g. "integer", "character varying", "jsonb"
+	Class    TypeClass
+
+	PrimaryKey bool // part of the table's primary key
+
+	// table columns only (ListColumns), not result sets
+	NotNull   bool
+	Default   string // default expression, "identity", or "" for none
+	Generated bool   // computed by the database, can't be written
+}
+
+// Value is a single cell. Raw holds the value as decoded by the driver
+// (nil when Null), Display the text shown in the grid.
+type Value struct {
+	Raw     any
+	Null    bool
+	Display string
+}
+
+// NullValue is the cell used for SQL NULL.
+var NullValue = Value{Null: true}
+
+// Text returns a non-NULL value whose raw and display forms are s.
+func Text(s string) Value {
+	return Value{Raw: s, Display: s}
+}
+
+// ColumnNames returns the names of cols in order.
+func ColumnNames(cols []Column) []string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	return names
 }
 
 // Page of rows.
 type RowPage struct {
-	Columns   []string
-	Rows      [][]string
+	Columns   []Column
+	Rows      [][]Value
 	TotalRows int
 	Offset    int
 }
@@ -31,6 +110,85 @@ type DB interface {
 	Close() error
 
 	ListTables(ctx context.Context) ([]string, error)
+	ListColumns(ctx context.Context, table string) ([]Column, error)
 	FetchRows(ctx context.Context, table string, opts QueryOptions) (RowPage, error)
-	DeleteRows(ctx context.Context, table string, where string) (int64, error)
+	// StreamRows hands fn every row FetchRows would match, opts.Limit rows
+	// at a time, all read from one snapshot; opts.Offset is ignored. fn is
+	// called at least once, so the columns are known even without rows.
+	StreamRows(ctx context.Context, table string, opts QueryOptions, fn func(RowPage) error) error
+	// CheckFilter plans the query FetchRows would run with opts, without
+	// running it. A filter the database rejects is reported as *FilterError.
+	CheckFilter(ctx context.Context, table string, opts QueryOptions) error
+	// SearchTables looks for text in every searchable column of every
+	// table, like a Search, and returns the columns with hits.
+	SearchTables(ctx context.Context, text string) ([]SearchHit, error)
+	// DeleteRows deletes in a transaction that is rolled back unless exactly
+	// expect rows were deleted (any count when expect is negative), and
+	// returns the images of the deleted rows.
+	DeleteRows(ctx context.Context, table string, where string, expect int64) ([]RowImage, error)
+
+	// CopyRows bulk-loads rows into the given columns in one transaction;
+	// nothing is kept if any row fails.
+	CopyRows(ctx context.Context, table string, columns []string, src RowSource) (int64, error)
+
+	// KeyColumns returns the columns that identify a row: the primary key,
+	// else the narrowest unique key over NOT NULL columns; nil if neither.
+	KeyColumns(ctx context.Context, table string) ([]string, error)
+	// UpdateRow sets one column of the row with the given key; value is a
+	// string parsed as the column type, or nil for NULL. It returns the
+	// image of the row before the update, none if no row matched. See
+	// UpdateSQL.
+	UpdateRow(ctx context.Context, table string, key RowKey, column string, value any) ([]RowImage, error)
+	// DeleteByKeys deletes the rows with the given keys (all over the same
+	// columns) in a transaction that is rolled back unless every key
+	// matched a row, and returns their images. See DeleteKeysSQL.
+	DeleteByKeys(ctx context.Context, table string, keys []RowKey) ([]RowImage, error)
+	// InsertRow inserts one row, leaving columns that are not listed at
+	// their default, and returns it as stored. See InsertSQL.
+	InsertRow(ctx context.Context, table string, columns []string, values []any) (RowPage, error)
+
+	// RestoreRows inserts deleted rows back from their images, filling the
+	// given columns. See RestoreSQL.
+	RestoreRows(ctx context.Context, table string, columns []string, images []RowImage) (int64, error)
+	// RevertColumn sets column back to its value in the images, in a
+	// transaction that is rolled back unless every row was found by key.
+	// value is what the column was set to, which finds the rows when the
+	// column is part of the key. See RevertSQL.
+	RevertColumn(ctx context.Context, table string, key []string, column string, value any, images []RowImage) (int64, error)
+
+	// Begin opens a transaction on a dedicated connection; every call runs
+	// inside it until Commit or Rollback. A statement that fails is undone
+	// on its own without aborting the transaction.
+	Begin(ctx context.Context) error
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+	InTransaction() bool
 }
+
+// RowSource feeds CopyRows one row at a time. Values are strings (parsed
+// by the database as text input for the column type) or nil for NULL.
+type RowSource interface {
+	Next() bool
+	Values() ([]any, error)
+	Err() error
+}
+
+// CopyError is returned by CopyRows when the database rejects a row.
+type CopyError struct {
+	Row int // 1-based row of the source, 0 if unknown
+	Err error
+}
+
+func (e *CopyError) Error() string { return e.Err.Error() }
+func (e *CopyError) Unwrap() error { return e.Err }
+
+// FilterError is returned by CheckFilter when the database rejects a
+// filter. Position is the 1-based character of QueryOptions.Filter the
+// error points at, 0 when it points elsewhere or nowhere.
+type FilterError struct {
+	Position int
+	Err      error
+}
+
+func (e *FilterError) Error() string { return e.Err.Error() }
+func (e *FilterError) Unwrap() error { return e.Err }
@@ -0,0 +1,135 @@
+package db
+
+import "strings"
+
+// Op is the comparison of a filter condition.
+type Op int
+
+const (
+	OpEq Op = iota
+	OpNe
+	OpLt
+	OpLe
+	OpGt
+	OpGe
+	OpLike  // on the value as text
+	OpILike // on the value as text, ignoring case
+	OpIsNull
+	OpNotNull
+)
+
+// Ops lists every operator, in the order a menu shows them.
+var Ops = []Op{OpEq, OpNe, OpLt, OpLe, OpGt, OpGe, OpLike, OpILike, OpIsNull, OpNotNull}
+
+var opText = [...]string{"=", "<>", "<", "<=", ">", ">=", "LIKE", "ILIKE", "IS NULL", "IS NOT NULL"}
+
+func (o Op) String() string { return opText[o] }
+
+// Unary reports whether the operator takes no value.
+func (o Op) Unary() bool { return o == OpIsNull || o == OpNotNull }
+
+// Expr is a structured filter: a Cond, a Search, or a Group of them. Backends
+// render it with the values bound as parameters; String is for display.
+type Expr interface {
+	String() string
+	isExpr()
+}
+
+// Cond compares a column with a value. The value is text, parsed by the
+// database as the column type; it is ignored for unary operators.
+type Cond struct {
+	Column string
+	Op     Op
+	Value  string
+}
+
+// Group joins expressions with AND, or with OR.
+type Group struct {
+	Or    bool
+	Items []Expr
+}
+
+// Search matches Text anywhere in the text form of any of the columns,
+// ignoring case.
+type Search struct {
+	Columns []string
+	Text    string
+}
+
+// SearchHit counts the rows of a table with a match in one column.
+type SearchHit struct {
+	Table  string
+	Column string
+	Rows   int64
+}
+
+func (Cond) isExpr()   {}
+func (Group) isExpr()  {}
+func (Search) isExpr() {}
+
+// String renders the condition as SQL with the value as a quoted literal.
+func (c Cond) String() string {
+	switch {
+	case c.Op.Unary():
+		return QuoteIdent(c.Column) + " " + c.Op.String()
+	case c.Op == OpLike || c.Op == OpILike:
+		return QuoteIdent(c.Column) + "::text " + c.Op.String() + " " + QuoteLiteral(c.Value)
+	}
+	return QuoteIdent(c.Column) + " " + c.Op.String() + " " + QuoteLiteral(c.Value)
+}
+
+// String renders the group as SQL, parenthesizing nested groups.
+func (g Group) String() string {
+	parts := make([]string, len(g.Items))
+	for i, e := range g.Items {
+		parts[i] = e.String()
+		if sub, ok := e.(Group); ok && len(sub.Items) > 1 {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	if g.Or {
+		return strings.Join(parts, " OR ")
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// Pattern is the ILIKE pattern for the search text.
+func (s Search) Pattern() string {
+	return "%" + EscapeLike(s.Text) + "%"
+}
+
+// String renders the search as SQL, parenthesized when it spans several
+// columns; no columns match nothing.
+func (s Search) String() string {
+	if len(s.Columns) == 0 {
+		return "FALSE"
+	}
+	parts := make([]string, len(s.Columns))
+	for i, c := range s.Columns {
+		parts[i] = QuoteIdent(c) + "::text ILIKE " + QuoteLiteral(s.Pattern())
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+// Searchable reports whether a search looks at the column. Binary values
+// are left out: their text form is hex, not what anyone searches for.
+func Searchable(c Column) bool {
+	return c.Class != ClassBinary
+}
+
+// likeEscaper makes text match itself literally in a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes the LIKE wildcards in s, and the backslash that
+// escapes them.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// QuoteLiteral single-quotes s as an SQL string literal.
+func QuoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// copyLine finds the row number in a COPY error context, e.g.
+// "COPY users, line 42, column age: \"x\"".
+var copyLine = regexp.MustCompile(`COPY [^,]+, line (\d+)`)
+
+// CopyRows implements db.DB using COPY FROM STDIN inside a transaction.
+func (p *PostgresDB) CopyRows(ctx context.Context, table string, columns []string, src db.RowSource) (int64, error) {
+	var n int64
+	err := p.run(ctx, func(q querier) error {
+		tx, err := q.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		// no-op after a successful commit
+		defer tx.Rollback(context.Background())
+
+		n, err = tx.CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromSource(src))
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				if m := copyLine.FindStringSubmatch(pgErr.Where); m != nil {
+					row, _ := strconv.Atoi(m[1])
+					return &db.CopyError{Row: row, Err: err}
+				}
+			}
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
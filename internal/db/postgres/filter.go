@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// CheckFilter implements db.DB with EXPLAIN, which parses and plans the
+// query, binding the values of the structured filter, without running it.
+func (p *PostgresDB) CheckFilter(ctx context.Context, table string, opts db.QueryOptions) error {
+	where, args := whereClause(opts, nil)
+	head := "EXPLAIN SELECT * FROM " + db.QuoteIdent(table)
+	err := p.run(ctx, func(q querier) error {
+		_, err := q.Exec(ctx, head+where, args...)
+		return err
+	})
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	fe := &db.FilterError{Err: err}
+	// the server counts characters of the whole statement; the raw filter
+	// comes first in the WHERE clause
+	if start := strings.Index(where, opts.Filter); opts.Filter != "" && start >= 0 && pgErr.Position > 0 {
+		pos := int(pgErr.Position) - utf8.RuneCountInString(head+where[:start])
+		if pos >= 1 && pos <= utf8.RuneCountInString(opts.Filter)+1 {
+			fe.Position = pos
+		}
+	}
+	return fe
+}
+
+// whereClause renders the filters of opts as " WHERE ...", or "" for none.
+// Values of the structured filter are appended to args and referenced as
+// $n after the ones already there.
+func whereClause(opts db.QueryOptions, args []any) (string, []any) {
+	var conds []string
+	if strings.TrimSpace(opts.Filter) != "" {
+		conds = append(conds, opts.Filter)
+	}
+	if opts.Where != nil {
+		var sql string
+		sql, args = renderExpr(opts.Where, args)
+		if sql != "" {
+			conds = append(conds, sql)
+		}
+	}
+
+	switch len(conds) {
+	case 0:
+		return "", args
+	case 1:
+		return " WHERE " + conds[0], args
+	}
+	return " WHERE (" + strings.Join(conds, ") AND (") + ")", args
+}
+
+// renderExpr renders e with its values bound as parameters. Identifiers
+// are quoted, so nothing the user typed becomes SQL.
+func renderExpr(e db.Expr, args []any) (string, []any) {
+	switch e := e.(type) {
+	case db.Cond:
+		col := db.QuoteIdent(e.Column)
+		switch {
+		case e.Op.Unary():
+			return col + " " + e.Op.String(), args
+		case e.Op == db.OpLike || e.Op == db.OpILike:
+			col += "::text"
+		}
+		// sent as text, so the server parses it as the column type
+		args = append(args, e.Value)
+		return fmt.Sprintf("%s %s $%d", col, e.Op, len(args)), args
+
+	case db.Search:
+		if len(e.Columns) == 0 {
+			return "FALSE", args
+		}
+		args = append(args, e.Pattern())
+		parts := make([]string, len(e.Columns))
+		for i, c := range e.Columns {
+			parts[i] = fmt.Sprintf("%s::text ILIKE $%d", db.QuoteIdent(c), len(args))
+		}
+		if len(parts) == 1 {
+			return parts[0], args
+		}
+		return "(" + strings.Join(parts, " OR ") + ")", args
+
+	case db.Group:
+		var parts []string
+		for _, item := range e.Items {
+			var sql string
+			sql, args = renderExpr(item, args)
+			if sql == "" {
+				continue
+			}
+			if sub, ok := item.(db.Group); ok && len(sub.Items) > 1 {
+				sql = "(" + sql + ")"
+			}
+			parts = append(parts, sql)
+		}
+		if e.Or {
+			return strings.Join(parts, " OR "), args
+		}
+		return strings.Join(parts, " AND "), args
+	}
+	return "", args
+}
@@ -0,0 +1,453 @@
+package postgres
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// typeMap is used to look up element types of built-in arrays and ranges.
+var typeMap = pgtype.NewMap()
+
+// registerTypes makes json and jsonb, and arrays of them, decode to the
+// text sent by the server. Decoding into Go maps would round integers above
+// 2^53 and lose the key order and duplicate keys of json.
+func registerTypes(m *pgtype.Map) {
+	jsonType := &pgtype.Type{Name: "json", OID: pgtype.JSONOID, Codec: &pgtype.JSONCodec{Marshal: json.Marshal, Unmarshal: unmarshalRaw}}
+	jsonbType := &pgtype.Type{Name: "jsonb", OID: pgtype.JSONBOID, Codec: &pgtype.JSONBCodec{Marshal: json.Marshal, Unmarshal: unmarshalRaw}}
+	m.RegisterType(jsonType)
+	m.RegisterType(jsonbType)
+	m.RegisterType(&pgtype.Type{Name: "_json", OID: pgtype.JSONArrayOID, Codec: &pgtype.ArrayCodec{ElementType: jsonType}})
+	m.RegisterType(&pgtype.Type{Name: "_jsonb", OID: pgtype.JSONBArrayOID, Codec: &pgtype.ArrayCodec{ElementType: jsonbType}})
+}
+
+// unmarshalRaw keeps JSON text as a string when decoding into an interface,
+// and unmarshals it into anything else.
+func unmarshalRaw(data []byte, v any) error {
+	if p, ok := v.(*any); ok {
+		*p = string(data)
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// formatter renders values decoded by pgx the way psql displays them.
+type formatter struct {
+	loc    *time.Location // zone used for timestamptz
+	binary db.BinaryFormat
+}
+
+// value wraps a decoded driver value into a db.Value.
+func (f formatter) value(oid uint32, v any) db.Value {
+	if v == nil {
+		return db.NullValue
+	}
+	if u, ok := v.(pgtype.UUID); ok && !u.Valid {
+		return db.NullValue
+	}
+	return db.Value{Raw: v, Display: f.format(oid, v)}
+}
+
+// format renders a single non-NULL value of the given type.
+func (f formatter) format(oid uint32, v any) string {
+	switch oid {
+	case pgtype.ByteaOID:
+		if b, ok := v.([]byte); ok {
+			return formatBytea(b, f.binary)
+		}
+	case pgtype.DateOID:
+		if t, ok := v.(time.Time); ok {
+			return formatDate(t)
+		}
+	case pgtype.TimestampOID:
+		if t, ok := v.(time.Time); ok {
+			return formatTimestamp(t, false)
+		}
+	case pgtype.TimestamptzOID:
+		if t, ok := v.(time.Time); ok {
+			return formatTimestamp(t.In(f.location()), true)
+		}
+	case pgtype.RecordOID:
+		if fields, ok := v.([]any); ok {
+			return f.formatRecord(fields)
+		}
+	case pgtype.InetOID:
+		if p, ok := v.(netip.Prefix); ok && p.Bits() == p.Addr().BitLen() {
+			return p.Addr().String()
+		}
+	case pgtype.JSONOID, pgtype.JSONBOID:
+		if s, ok := v.(string); ok {
+			// one line in the grid: Compact only drops the whitespace, so
+			// key order, duplicate keys and digits stay as sent
+			var b bytes.Buffer
+			if json.Compact(&b, []byte(s)) == nil {
+				return b.String()
+			}
+			return s
+		}
+	}
+
+	switch val := v.(type) {
+	case string:
+		return val
+	case bool:
+		if val {
+			return "t"
+		}
+		return "f"
+	case int16, int32, int64, int, uint32, uint64:
+		return fmt.Sprint(val)
+	case float64:
+		return formatFloat(val, 64)
+	case float32:
+		return formatFloat(float64(val), 32)
+	case pgtype.InfinityModifier:
+		switch val {
+		case pgtype.Infinity:
+			return "infinity"
+		case pgtype.NegativeInfinity:
+			return "-infinity"
+		}
+		return ""
+	case time.Time:
+		return formatTimestamp(val.In(f.location()), true)
+	case pgtype.Time:
+		return formatClock(val.Microseconds)
+	case pgtype.Interval:
+		return formatInterval(val)
+	case [16]byte:
+		if oid == pgtype.UUIDOID || oid == pgtype.UUIDArrayOID {
+			return uuid.UUID(val).String()
+		}
+		return formatBytea(val[:], f.binary)
+	case pgtype.UUID:
+		return val.String()
+	case netip.Prefix:
+		return val.String()
+	case pgtype.Hstore:
+		return formatHstore(val)
+	case []any:
+		return f.formatArray(elementOID(oid), val)
+	case pgtype.Range[any]:
+		return f.formatRange(elementOID(oid), val)
+	case pgtype.Multirange[pgtype.Range[any]]:
+		return f.formatMultirange(elementOID(oid), val)
+
+	// text of types pgx has no codec for (citext, ltree, ...)
+	case []byte:
+		return string(val)
+
+	case driver.Valuer:
+		// numeric, bits, geometric types, ... know their own text form
+		if dv, err := val.Value(); err == nil {
+			if s, ok := dv.(string); ok {
+				return s
+			}
+		}
+	case fmt.Stringer:
+		return val.String()
+	}
+
+	return fmt.Sprint(v)
+}
+
+func (f formatter) location() *time.Location {
+	if f.loc == nil {
+		return time.Local
+	}
+	return f.loc
+}
+
+// elementOID returns the element type of a built-in array, range or
+// multirange type, or 0 when unknown.
+func elementOID(oid uint32) uint32 {
+	t, ok := typeMap.TypeForOID(oid)
+	if !ok {
+		return 0
+	}
+	switch c := t.Codec.(type) {
+	case *pgtype.ArrayCodec:
+		return c.ElementType.OID
+	case *pgtype.RangeCodec:
+		return c.ElementType.OID
+	case *pgtype.MultirangeCodec:
+		return c.ElementType.OID
+	}
+	return 0
+}
+
+// ----- Scalars -----
+
+// formatBytea renders binary data in the requested bytea_output style.
+func formatBytea(b []byte, format db.BinaryFormat) string {
+	switch format {
+	case db.BinaryEscape:
+		var sb strings.Builder
+		for _, c := range b {
+			switch {
+			case c == '\\':
+				sb.WriteString(`\\`)
+			case c < 0x20 || c > 0x7e:
+				fmt.Fprintf(&sb, `\%03o`, c)
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return sb.String()
+	case db.BinaryBase64:
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	return `\x` + hex.EncodeToString(b)
+}
+
+// formatFloat matches Postgres' shortest-exact float output, which switches
+// to exponent notation outside the range of DBL_DIG/FLT_DIG digits.
+func formatFloat(x float64, bitSize int) string {
+	switch {
+	case math.IsNaN(x):
+		return "NaN"
+	case math.IsInf(x, 1):
+		return "Infinity"
+	case math.IsInf(x, -1):
+		return "-Infinity"
+	case x == 0:
+		if math.Signbit(x) {
+			return "-0"
+		}
+		return "0"
+	}
+
+	digits := 15
+	if bitSize == 32 {
+		digits = 6
+	}
+
+	// the decimal exponent, read back from the shortest form since
+	// math.Log10 rounds down just below powers of ten
+	e := strconv.FormatFloat(x, 'e', -1, bitSize)
+	exp, _ := strconv.Atoi(e[strings.IndexByte(e, 'e')+1:])
+	if exp < -4 || exp >= digits {
+		return e
+	}
+	return strconv.FormatFloat(x, 'f', -1, bitSize)
+}
+
+// formatDate renders YYYY-MM-DD, with a " BC" suffix for years before 1.
+func formatDate(t time.Time) string {
+	year, bc := t.Year(), ""
+	if year <= 0 {
+		year, bc = 1-year, " BC"
+	}
+	return fmt.Sprintf("%04d-%02d-%02d%s", year, t.Month(), t.Day(), bc)
+}
+
+// formatTimestamp renders a timestamp like psql, optionally with the UTC
+// offset ("+05:30", "-08").
+func formatTimestamp(t time.Time, withZone bool) string {
+	year, bc := t.Year(), ""
+	if year <= 0 {
+		year, bc = 1-year, " BC"
+	}
+
+	s := fmt.Sprintf("%04d-%02d-%02d %s", year, t.Month(), t.Day(),
+		formatClock(int64(t.Hour())*3600e6+int64(t.Minute())*60e6+int64(t.Second())*1e6+int64(t.Nanosecond()/1000)))
+
+	if withZone {
+		_, offset := t.Zone()
+		sign := "+"
+		if offset < 0 {
+			sign, offset = "-", -offset
+		}
+		s += fmt.Sprintf("%s%02d", sign, offset/3600)
+		if rest := offset % 3600; rest != 0 {
+			s += fmt.Sprintf(":%02d", rest/60)
+			if rest%60 != 0 {
+				s += fmt.Sprintf(":%02d", rest%60)
+			}
+		}
+	}
+	return s + bc
+}
+
+// formatClock renders HH:MM:SS[.ffffff] from a non-negative microsecond count.
+func formatClock(us int64) string {
+	hours := us / 3600e6
+	us -= hours * 3600e6
+	minutes := us / 60e6
+	us -= minutes * 60e6
+	seconds := us / 1e6
+	us -= seconds * 1e6
+
+	s := fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+	if us != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%06d", us), "0")
+	}
+	return s
+}
+
+// formatInterval follows IntervalStyle = postgres, e.g.
+// "1 year 2 mons -3 days +04:05:06.5".
+func formatInterval(iv pgtype.Interval) string {
+	var (
+		parts    []string
+		isBefore bool
+		isZero   = true
+	)
+
+	addPart := func(value int64, unit string) {
+		if value == 0 {
+			return
+		}
+		sign := ""
+		if !isZero && isBefore && value > 0 {
+			sign = "+"
+		}
+		if value != 1 {
+			unit += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%s%d %s", sign, value, unit))
+		isBefore = value < 0
+		isZero = false
+	}
+
+	addPart(int64(iv.Months/12), "year")
+	addPart(int64(iv.Months%12), "mon")
+	addPart(int64(iv.Days), "day")
+
+	if iv.Microseconds != 0 || isZero {
+		us, sign := iv.Microseconds, ""
+		switch {
+		case us < 0:
+			us, sign = -us, "-"
+		case isBefore:
+			sign = "+"
+		}
+		parts = append(parts, sign+formatClock(us))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatHstore renders `"k"=>"v", "k2"=>NULL`, ordered like Postgres
+// (shorter keys first).
+func formatHstore(h pgtype.Hstore) string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	escape := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		v := "NULL"
+		if h[k] != nil {
+			v = escape(*h[k])
+		}
+		parts[i] = escape(k) + "=>" + v
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ----- Containers -----
+
+// formatArray renders {a,"b c",NULL}; nested slices become nested braces.
+func (f formatter) formatArray(elemOID uint32, items []any) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		switch e := item.(type) {
+		case nil:
+			parts[i] = "NULL"
+		case []any:
+			parts[i] = f.formatArray(elemOID, e)
+		default:
+			parts[i] = quoteArrayElement(f.format(elemOID, e))
+		}
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func quoteArrayElement(s string) string {
+	if s != "" && !strings.EqualFold(s, "NULL") && !strings.ContainsAny(s, "{},\"\\ \t\n\r\v\f") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// formatRecord renders a composite value as (a,"b c",) with NULL fields left
+// empty.
+func (f formatter) formatRecord(fields []any) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		if field != nil {
+			parts[i] = quoteBound(f.format(0, field))
+		}
+	}
+	return "(" + strings.Join(parts, ",") + ")"
+}
+
+// formatRange renders [lower,upper), (,upper], empty, ...
+func (f formatter) formatRange(elemOID uint32, r pgtype.Range[any]) string {
+	if r.LowerType == pgtype.Empty {
+		return "empty"
+	}
+
+	var sb strings.Builder
+	if r.LowerType == pgtype.Inclusive {
+		sb.WriteString("[")
+	} else {
+		sb.WriteString("(")
+	}
+	if r.LowerType != pgtype.Unbounded && r.Lower != nil {
+		sb.WriteString(quoteBound(f.format(elemOID, r.Lower)))
+	}
+	sb.WriteString(",")
+	if r.UpperType != pgtype.Unbounded && r.Upper != nil {
+		sb.WriteString(quoteBound(f.format(elemOID, r.Upper)))
+	}
+	if r.UpperType == pgtype.Inclusive {
+		sb.WriteString("]")
+	} else {
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+func (f formatter) formatMultirange(rangeOID uint32, mr pgtype.Multirange[pgtype.Range[any]]) string {
+	elemOID := elementOID(rangeOID)
+	parts := make([]string, len(mr))
+	for i, r := range mr {
+		parts[i] = f.formatRange(elemOID, r)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// quoteBound quotes range bounds (and composite fields) the way range_out
+// does: special characters force quotes, and quotes/backslashes are doubled.
+func quoteBound(s string) string {
+	if s != "" && !strings.ContainsAny(s, "()[],\"\\ \t\n\r\v\f") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `""`).Replace(s) + `"`
+}
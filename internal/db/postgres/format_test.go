@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestFormat(t *testing.T) {
+	kolkata := time.FixedZone("IST", 5*3600+30*60)
+	lmt := time.FixedZone("LMT", -(4*3600 + 56*60 + 2))
+	ts := time.Date(2024, 3, 5, 14, 7, 9, 123000000, time.UTC)
+
+	f := formatter{loc: kolkata}
+
+	tests := []struct {
+		name string
+		oid  uint32
+		v    any
+		want string
+	}{
+		// intervals
+		{"interval zero", pgtype.IntervalOID, pgtype.Interval{Valid: true}, "00:00:00"},
+		{"interval day", pgtype.IntervalOID, pgtype.Interval{Days: 1, Valid: true}, "1 day"},
+		{"interval mixed signs", pgtype.IntervalOID, pgtype.Interval{Months: 14, Days: -3, Microseconds: 14706500000, Valid: true}, "1 year 2 mons -3 days +04:05:06.5"},
+		{"interval negative month", pgtype.IntervalOID, pgtype.Interval{Months: -1, Valid: true}, "-1 mons"},
+		{"interval negative time", pgtype.IntervalOID, pgtype.Interval{Microseconds: -3600e6, Valid: true}, "-01:00:00"},
+		{"interval over a day", pgtype.IntervalOID, pgtype.Interval{Microseconds: 30 * 3600e6, Valid: true}, "30:00:00"},
+
+		// arrays
+		{"int array", pgtype.Int4ArrayOID, []any{int32(1), nil, int32(3)}, "{1,NULL,3}"},
+		{"text array quoting", pgtype.TextArrayOID, []any{"a b", "", "NULL", `q"`, `b\s`, "plain"}, `{"a b","","NULL","q\"","b\\s",plain}`},
+		{"nested array", pgtype.Int4ArrayOID, []any{[]any{int32(1), int32(2)}, []any{int32(3), int32(4)}}, "{{1,2},{3,4}}"},
+		{"bool array", pgtype.BoolArrayOID, []any{true, false}, "{t,f}"},
+		{"uuid array", pgtype.UUIDArrayOID, []any{[16]byte{0x12, 0x34, 15: 0xff}}, "{12340000-0000-0000-0000-0000000000ff}"},
+
+		// ranges
+		{"int range", pgtype.Int4rangeOID, pgtype.Range[any]{Lower: int32(1), Upper: int32(10), LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true}, "[1,10)"},
+		{"empty range", pgtype.Int4rangeOID, pgtype.Range[any]{LowerType: pgtype.Empty, UpperType: pgtype.Empty, Valid: true}, "empty"},
+		{"unbounded range", pgtype.Int8rangeOID, pgtype.Range[any]{Upper: int64(5), LowerType: pgtype.Unbounded, UpperType: pgtype.Exclusive, Valid: true}, "(,5)"},
+		{"timestamp range", pgtype.TsrangeOID, pgtype.Range[any]{Lower: ts, Upper: ts.Add(24 * time.Hour), LowerType: pgtype.Inclusive, UpperType: pgtype.Inclusive, Valid: true}, `["2024-03-05 14:07:09.123","2024-03-06 14:07:09.123"]`},
+		{"multirange", pgtype.Int4multirangeOID, pgtype.Multirange[pgtype.Range[any]]{
+			{Lower: int32(1), Upper: int32(3), LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true},
+			{Lower: int32(7), LowerType: pgtype.Inclusive, UpperType: pgtype.Unbounded, Valid: true},
+		}, "{[1,3),[7,)}"},
+
+		// numerics
+		{"numeric", pgtype.NumericOID, pgtype.Numeric{Int: big.NewInt(12345), Exp: -2, Valid: true}, "123.45"},
+		{"numeric positive exponent", pgtype.NumericOID, pgtype.Numeric{Int: big.NewInt(12), Exp: 2, Valid: true}, "1200"},
+		{"numeric negative", pgtype.NumericOID, pgtype.Numeric{Int: big.NewInt(-5), Exp: -3, Valid: true}, "-0.005"},
+		{"numeric NaN", pgtype.NumericOID, pgtype.Numeric{NaN: true, Valid: true}, "NaN"},
+		{"numeric infinity", pgtype.NumericOID, pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true}, "Infinity"},
+		{"int8", pgtype.Int8OID, int64(math.MaxInt64), "9223372036854775807"},
+		{"float8", pgtype.Float8OID, 0.1, "0.1"},
+		{"float8 below exponent form", pgtype.Float8OID, 1e14, "100000000000000"},
+		{"float8 large", pgtype.Float8OID, 1e15, "1e+15"},
+		{"float8 small", pgtype.Float8OID, 0.0001, "0.0001"},
+		{"float8 tiny", pgtype.Float8OID, 0.00001, "1e-05"},
+		{"float8 negative zero", pgtype.Float8OID, math.Copysign(0, -1), "-0"},
+		{"float8 infinity", pgtype.Float8OID, math.Inf(-1), "-Infinity"},
+		{"float4", pgtype.Float4OID, float32(1234567), "1.234567e+06"},
+
+		// json
+		{"json compacted", pgtype.JSONOID, "{\n  \"b\": 1,\n  \"a\": [1, 2]\n}", `{"b":1,"a":[1,2]}`},
+		{"json duplicate keys and digits", pgtype.JSONOID, `{"a": 1, "a": 12345678901234567890.10}`, `{"a":1,"a":12345678901234567890.10}`},
+		{"json string spacing", pgtype.JSONBOID, `{"k": "a  b"}`, `{"k":"a  b"}`},
+		{"json array", pgtype.JSONArrayOID, []any{"{\"a\": 1}", nil}, `{"{\"a\":1}",NULL}`},
+
+		// dates and timestamps
+		{"date", pgtype.DateOID, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), "2024-03-05"},
+		{"date BC", pgtype.DateOID, time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), "0001-01-01 BC"},
+		{"timestamp", pgtype.TimestampOID, ts, "2024-03-05 14:07:09.123"},
+		{"timestamp whole second", pgtype.TimestampOID, ts.Truncate(time.Second), "2024-03-05 14:07:09"},
+		{"timestamptz in zone", pgtype.TimestamptzOID, ts, "2024-03-05 19:37:09.123+05:30"},
+		{"timestamptz BC", pgtype.TimestamptzOID, time.Date(-99, 6, 1, 0, 0, 0, 0, kolkata), "0100-06-01 00:00:00+05:30 BC"},
+		{"timestamp infinity", pgtype.TimestampOID, pgtype.Infinity, "infinity"},
+		{"time", pgtype.TimeOID, pgtype.Time{Microseconds: 13*3600e6 + 5e5, Valid: true}, "13:00:00.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.format(tt.oid, tt.v); got != tt.want {
+				t.Errorf("format(%d, %#v) = %q, want %q", tt.oid, tt.v, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("offset with seconds", func(t *testing.T) {
+		got := formatTimestamp(time.Date(1880, 1, 1, 0, 0, 0, 0, lmt), true)
+		if want := "1880-01-01 00:00:00-04:56:02"; got != want {
+			t.Errorf("formatTimestamp = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestFormatBytea(t *testing.T) {
+	b := []byte{0x00, 'a', '\\', 0xff}
+	tests := []struct {
+		format db.BinaryFormat
+		want   string
+	}{
+		{db.BinaryHex, `\x00615cff`},
+		{db.BinaryEscape, `\000a\\\377`},
+		{db.BinaryBase64, "AGFc/w=="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			if got := formatBytea(b, tt.format); got != tt.want {
+				t.Errorf("formatBytea(%v) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,23 +4,41 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type PostgresDB struct {
 	pool *pgxpool.Pool
+	fmt  formatter
+
+	// transaction opened with Begin, on a connection taken from the pool
+	txMu   sync.Mutex
+	tx     pgx.Tx
+	txConn *pgxpool.Conn
+
+	// type metadata cache, keyed by OID
+	typesMu sync.Mutex
+	types   map[uint32]typeInfo
+}
+
+type typeInfo struct {
+	name     string
+	category string // pg_type.typcategory
 }
 
 func New() *PostgresDB {
-	return &PostgresDB{}
+	return &PostgresDB{types: make(map[uint32]typeInfo)}
 }
 
 func (p *PostgresDB) buildDSN(cfg db.ConnConfig) string {
-	return fmt.Sprintf(
+	dsn := fmt.Sprintf(
 		"postgres://%s:%s@%s:%s/%s?sslmode=disable",
 		cfg.User,
 		cfg.Password,
@@ -28,32 +46,62 @@ func (p *PostgresDB) buildDSN(cfg db.ConnConfig) string {
 		cfg.Port,
 		cfg.Database,
 	)
-}
-
-func (p *PostgresDB) DeleteRows(ctx context.Context, table string, where string) (int64, error) {
-	if p.pool == nil {
-		return 0, fmt.Errorf("database not connected")
+	if cfg.ReadOnly {
+		// sent as a startup parameter, so the server enforces it
+		dsn += "&default_transaction_read_only=on"
 	}
+	return dsn
+}
 
+func (p *PostgresDB) DeleteRows(ctx context.Context, table string, where string, expect int64) ([]db.RowImage, error) {
 	if strings.TrimSpace(where) == "" {
-		return 0, fmt.Errorf("empty WHERE clause is not allowed for DELETE")
+		return nil, fmt.Errorf("empty WHERE clause is not allowed for DELETE")
 	}
 
-	query := fmt.Sprintf(`DELETE FROM %s WHERE %s`, table, where)
+	query := db.DeleteSQL(table, where)
 
-	cmdTag, err := p.pool.Exec(ctx, query)
+	var images []db.RowImage
+	err := p.run(ctx, func(q querier) error {
+		tx, err := q.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		// no-op after a successful commit
+		defer tx.Rollback(context.Background())
+
+		rows, err := tx.Query(ctx, query)
+		if err != nil {
+			return err
+		}
+		images, err = scanImages(rows)
+		if err != nil {
+			return err
+		}
+		if n := int64(len(images)); expect >= 0 && n != expect {
+			return fmt.Errorf("%d rows matched instead of the %d previewed; nothing was deleted", n, expect)
+		}
+		return tx.Commit(ctx)
+	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-
-	return cmdTag.RowsAffected(), nil
+	return images, nil
 }
 
 // Connect implements db.DB.
 func (p *PostgresDB) Connect(ctx context.Context, cfg db.ConnConfig) error {
 	dsn := p.buildDSN(cfg)
 
-	pool, err := pgxpool.New(ctx, dsn)
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return err
+	}
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		registerTypes(conn.TypeMap())
+		return nil
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return err
 	}
@@ -63,12 +111,42 @@ func (p *PostgresDB) Connect(ctx context.Context, cfg db.ConnConfig) error {
 		return err
 	}
 
+	loc, err := resolveTimeZone(ctx, pool, cfg.TimeZone)
+	if err != nil {
+		pool.Close()
+		return err
+	}
+
 	p.pool = pool
+	p.fmt = formatter{loc: loc}
 	return nil
 }
 
-// Close db
+// resolveTimeZone picks the zone used to display timestamptz values: the
+// configured one, or else the server's TimeZone setting like psql does.
+func resolveTimeZone(ctx context.Context, pool *pgxpool.Pool, name string) (*time.Location, error) {
+	if name != "" {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", name, err)
+		}
+		return loc, nil
+	}
+
+	if err := pool.QueryRow(ctx, `SHOW TimeZone`).Scan(&name); err != nil {
+		return nil, err
+	}
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc, nil
+	}
+	return time.Local, nil
+}
+
+// Close db. An open transaction is rolled back.
 func (p *PostgresDB) Close() error {
+	if p.InTransaction() {
+		p.Rollback(context.Background())
+	}
 	if p.pool != nil {
 		p.pool.Close()
 	}
@@ -77,30 +155,86 @@ func (p *PostgresDB) Close() error {
 
 // ListTables
 func (p *PostgresDB) ListTables(ctx context.Context) ([]string, error) {
-	rows, err := p.pool.Query(ctx, `
-		SELECT table_name 
-		FROM information_schema.tables 
-		WHERE table_schema = 'public' AND table_type = 'BASE TABLE'
-		ORDER BY table_name;
-	`)
+	var tables []string
+	err := p.run(ctx, func(q querier) error {
+		rows, err := q.Query(ctx, `
+			SELECT table_name 
+			FROM information_schema.tables 
+			WHERE table_schema = 'public' AND table_type = 'BASE TABLE'
+			ORDER BY table_name;
+		`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return err
+			}
+			tables = append(tables, name)
+		}
+		return rows.Err()
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var tables []string
-	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
-			return nil, err
+	return tables, nil
+}
+
+// ListColumns returns every column of the table in definition order.
+func (p *PostgresDB) ListColumns(ctx context.Context, table string) ([]db.Column, error) {
+	var cols []db.Column
+	err := p.run(ctx, func(q querier) error {
+		rows, err := q.Query(ctx, `
+			SELECT a.attname, a.atttypid, format_type(a.atttypid, a.atttypmod), t.typcategory::text,
+			       a.attnotnull,
+			       CASE WHEN a.attidentity::text <> '' THEN 'identity'
+			            ELSE coalesce(pg_get_expr(d.adbin, d.adrelid), '') END,
+			       a.attgenerated::text <> ''
+			FROM pg_attribute a
+			JOIN pg_type t ON t.oid = a.atttypid
+			LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+			WHERE a.attrelid = $1::regclass AND a.attnum > 0 AND NOT a.attisdropped
+			ORDER BY a.attnum
+		`, db.QuoteIdent(table))
+		if err != nil {
+			return err
 		}
-		tables = append(tables, name)
-	}
-	if rows.Err() != nil {
-		return nil, rows.Err()
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				c        db.Column
+				category string
+			)
+			if err := rows.Scan(&c.Name, &c.TypeOID, &c.TypeName, &category, &c.NotNull, &c.Default, &c.Generated); err != nil {
+				return err
+			}
+			c.Class = classify(c.TypeOID, category)
+			cols = append(cols, c)
+		}
+		if rows.Err() != nil {
+			return rows.Err()
+		}
+		rows.Close()
+
+		pk, err := primaryKey(ctx, q, table)
+		if err != nil {
+			return err
+		}
+		for i := range cols {
+			cols[i].PrimaryKey = pk[cols[i].Name]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return tables, nil
+	return cols, nil
 }
 
 // FetchRows
@@ -109,93 +243,260 @@ func (p *PostgresDB) FetchRows(
 	table string,
 	opts db.QueryOptions,
 ) (db.RowPage, error) {
-	if p.pool == nil {
-		return db.RowPage{}, fmt.Errorf("database not connected")
-	}
-
-	// Build optional WHERE clause from filter
-	whereClause := ""
-	if opts.Filter != "" {
-		whereClause = " WHERE " + opts.Filter
-	}
+	// Build optional WHERE clause from the raw and structured filters
+	where, args := whereClause(opts, nil)
 
 	// 1) Get total row count for pagination
-	var total int
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s%s`, table, whereClause)
-	if err := p.pool.QueryRow(ctx, countQuery).Scan(&total); err != nil {
-		return db.RowPage{}, err
-	}
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s%s`, db.QuoteIdent(table), where)
 
 	// 2) Fetch current page
-	query := fmt.Sprintf(`SELECT * FROM %s%s LIMIT $1 OFFSET $2`, table, whereClause)
+	query := fmt.Sprintf(`SELECT %s FROM %s%s LIMIT $%d OFFSET $%d`, projection(opts), db.QuoteIdent(table), where, len(args)+1, len(args)+2)
+
+	var (
+		total int
+		cols  []db.Column
+		data  [][]db.Value
+	)
+	err := p.run(ctx, func(q querier) error {
+		if err := q.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+			return err
+		}
 
-	rows, err := p.pool.Query(ctx, query, opts.Limit, opts.Offset)
+		rows, err := q.Query(ctx, query, append(args, opts.Limit, opts.Offset)...)
+		if err != nil {
+			return err
+		}
+		cols, data, err = p.readRows(ctx, q, rows, table, opts.Binary)
+		return err
+	})
 	if err != nil {
 		return db.RowPage{}, err
 	}
-	defer rows.Close()
 
-	fds := rows.FieldDescriptions()
-	cols := make([]string, len(fds))
-	for i, fd := range fds {
-		cols[i] = string(fd.Name)
-	}
+	return db.RowPage{
+		Columns:   cols,
+		Rows:      data,
+		TotalRows: total,
+		Offset:    opts.Offset,
+	}, nil
+}
 
-	var data [][]string
-	for rows.Next() {
-		values, err := rows.Values()
+// StreamRows implements db.DB with a cursor. Outside a session it runs in a
+// read-only repeatable read transaction, so the count and every batch see
+// the same rows; in a session it sees the session's own changes.
+func (p *PostgresDB) StreamRows(ctx context.Context, table string, opts db.QueryOptions, fn func(db.RowPage) error) error {
+	where, args := whereClause(opts, nil)
+
+	return p.run(ctx, func(q querier) error {
+		var (
+			tx  pgx.Tx
+			err error
+		)
+		if pool, ok := q.(*pgxpool.Pool); ok {
+			tx, err = pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+		} else {
+			tx, err = q.Begin(ctx)
+		}
 		if err != nil {
-			return db.RowPage{}, err
+			return err
 		}
-		r := make([]string, len(values))
+		defer tx.Rollback(context.Background())
 
-		for i, v := range values {
-			switch val := v.(type) {
-
-			// UUID as [16]byte
-			case [16]byte:
-				if uid, err := uuid.FromBytes(val[:]); err == nil {
-					r[i] = uid.String()
-				} else {
-					r[i] = fmt.Sprint(val)
-				}
+		var total int
+		if err := tx.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s%s`, db.QuoteIdent(table), where), args...).Scan(&total); err != nil {
+			return err
+		}
+		declare := fmt.Sprintf(`DECLARE dbls_stream NO SCROLL CURSOR FOR SELECT %s FROM %s%s`, projection(opts), db.QuoteIdent(table), where)
+		if _, err := tx.Exec(ctx, declare, args...); err != nil {
+			return err
+		}
 
-			// UUID / binary as []byte
-			case []byte:
-				if uid, err := uuid.FromBytes(val); err == nil {
-					r[i] = uid.String()
-				} else {
-					r[i] = string(val)
+		fetch := fmt.Sprintf(`FETCH %d FROM dbls_stream`, max(opts.Limit, 1))
+		for offset := 0; ; {
+			rows, err := tx.Query(ctx, fetch)
+			if err != nil {
+				return err
+			}
+			cols, data, err := p.readRows(ctx, tx, rows, table, opts.Binary)
+			if err != nil {
+				return err
+			}
+			if len(data) > 0 || offset == 0 {
+				if err := fn(db.RowPage{Columns: cols, Rows: data, TotalRows: total, Offset: offset}); err != nil {
+					return err
 				}
+			}
+			if len(data) < max(opts.Limit, 1) {
+				return nil
+			}
+			offset += len(data)
+		}
+	})
+}
 
-			// pgx UUID type
-			case pgtype.UUID:
-				if val.Valid {
-					r[i] = val.String()
-				} else {
-					r[i] = "NULL"
-				}
+// projection is the select list for opts: the chosen columns, or *.
+func projection(opts db.QueryOptions) string {
+	if len(opts.Columns) == 0 {
+		return "*"
+	}
+	quoted := make([]string, len(opts.Columns))
+	for i, c := range opts.Columns {
+		quoted[i] = db.QuoteIdent(c)
+	}
+	return strings.Join(quoted, ", ")
+}
 
-			case nil:
-				r[i] = "NULL"
+// readRows formats every row of a result from table and describes its
+// columns. It closes rows.
+func (p *PostgresDB) readRows(ctx context.Context, q querier, rows pgx.Rows, table string, binary db.BinaryFormat) ([]db.Column, [][]db.Value, error) {
+	defer rows.Close()
 
-			case fmt.Stringer:
-				r[i] = val.String()
+	format := p.fmt
+	format.binary = binary
 
-			default:
-				r[i] = fmt.Sprint(v)
-			}
+	// copy: the driver reuses the backing array once the connection is released
+	fds := append([]pgconn.FieldDescription(nil), rows.FieldDescriptions()...)
+
+	var data [][]db.Value
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, nil, err
+		}
+		r := make([]db.Value, len(values))
+		for i, v := range values {
+			r[i] = format.value(fds[i].DataTypeOID, v)
 		}
 		data = append(data, r)
 	}
 	if rows.Err() != nil {
-		return db.RowPage{}, rows.Err()
+		return nil, nil, rows.Err()
 	}
+	rows.Close()
 
-	return db.RowPage{
-		Columns:   cols,
-		Rows:      data,
-		TotalRows: total,
-		Offset:    opts.Offset,
-	}, nil
+	cols, err := p.describeColumns(ctx, q, fds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pk, err := primaryKey(ctx, q, table)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range cols {
+		cols[i].PrimaryKey = pk[cols[i].Name]
+	}
+	return cols, data, nil
+}
+
+// describeColumns turns field descriptions into db.Column values, looking up
+// type names and categories in pg_type for OIDs that are not cached yet.
+func (p *PostgresDB) describeColumns(ctx context.Context, q querier, fds []pgconn.FieldDescription) ([]db.Column, error) {
+	p.typesMu.Lock()
+	var missing []uint32
+	for _, fd := range fds {
+		if _, ok := p.types[fd.DataTypeOID]; !ok {
+			missing = append(missing, fd.DataTypeOID)
+		}
+	}
+	p.typesMu.Unlock()
+
+	if len(missing) > 0 {
+		rows, err := q.Query(ctx, `
+			SELECT oid, format_type(oid, NULL), typcategory::text
+			FROM pg_type
+			WHERE oid = ANY($1)
+		`, missing)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		found := make(map[uint32]typeInfo)
+		for rows.Next() {
+			var (
+				oid  uint32
+				info typeInfo
+			)
+			if err := rows.Scan(&oid, &info.name, &info.category); err != nil {
+				return nil, err
+			}
+			found[oid] = info
+		}
+		if rows.Err() != nil {
+			return nil, rows.Err()
+		}
+
+		p.typesMu.Lock()
+		for oid, info := range found {
+			p.types[oid] = info
+		}
+		p.typesMu.Unlock()
+	}
+
+	p.typesMu.Lock()
+	defer p.typesMu.Unlock()
+
+	cols := make([]db.Column, len(fds))
+	for i, fd := range fds {
+		info := p.types[fd.DataTypeOID]
+		cols[i] = db.Column{
+			Name:     fd.Name,
+			TypeOID:  fd.DataTypeOID,
+			TypeName: info.name,
+			Class:    classify(fd.DataTypeOID, info.category),
+		}
+	}
+	return cols, nil
+}
+
+// classify maps a Postgres type to a display class.
+func classify(oid uint32, category string) db.TypeClass {
+	switch oid {
+	case pgtype.JSONOID, pgtype.JSONBOID:
+		return db.ClassJSON
+	case pgtype.ByteaOID:
+		return db.ClassBinary
+	case pgtype.OIDOID:
+		return db.ClassNumeric
+	}
+
+	switch category {
+	case "N":
+		return db.ClassNumeric
+	case "B":
+		return db.ClassBool
+	case "D", "T":
+		return db.ClassTime
+	case "S", "E":
+		return db.ClassText
+	}
+	return db.ClassOther
+}
+
+// primaryKey returns the names of the table's primary key columns.
+func primaryKey(ctx context.Context, q querier, table string) (map[string]bool, error) {
+	rows, err := q.Query(ctx, `
+		SELECT a.attname
+		FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indrelid = $1::regclass AND i.indisprimary
+	`, db.QuoteIdent(table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pk := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		pk[name] = true
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return pk, nil
 }
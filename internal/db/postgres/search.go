@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// SearchTables implements db.DB with one scan per table, counting the
+// matches of every column at once.
+func (p *PostgresDB) SearchTables(ctx context.Context, text string) ([]db.SearchHit, error) {
+	tables, err := p.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := db.Search{Text: text}.Pattern()
+	var hits []db.SearchHit
+	for _, table := range tables {
+		cols, err := p.ListColumns(ctx, table)
+		if err != nil {
+			return nil, fmt.Errorf("searching %s: %w", table, err)
+		}
+		var names, counts []string
+		for _, c := range cols {
+			if db.Searchable(c) {
+				names = append(names, c.Name)
+				counts = append(counts, fmt.Sprintf("count(*) FILTER (WHERE %s::text ILIKE $1)", db.QuoteIdent(c.Name)))
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+
+		query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(counts, ", "), db.QuoteIdent(table))
+		found := make([]int64, len(names))
+		dest := make([]any, len(names))
+		for i := range found {
+			dest[i] = &found[i]
+		}
+		err = p.run(ctx, func(q querier) error {
+			return q.QueryRow(ctx, query, pattern).Scan(dest...)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("searching %s: %w", table, err)
+		}
+
+		for i, n := range found {
+			if n > 0 {
+				hits = append(hits, db.SearchHit{Table: table, Column: names[i], Rows: n})
+			}
+		}
+	}
+	return hits, nil
+}
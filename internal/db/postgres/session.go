@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// querier is what statements run on: the pool, or the open transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// run calls fn with the pool, or, while a transaction is open, with a
+// savepoint inside it: a failing statement is rolled back on its own
+// instead of aborting the transaction. The transaction lives on a single
+// connection, so calls are serialized while it is open.
+func (p *PostgresDB) run(ctx context.Context, fn func(q querier) error) error {
+	if p.pool == nil {
+		return fmt.Errorf("database not connected")
+	}
+
+	p.txMu.Lock()
+	if p.tx == nil {
+		p.txMu.Unlock()
+		return fn(p.pool)
+	}
+	defer p.txMu.Unlock()
+
+	sp, err := p.tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(sp); err != nil {
+		sp.Rollback(context.Background())
+		return err
+	}
+	return sp.Commit(ctx)
+}
+
+// Begin implements db.DB: it takes a connection from the pool and opens a
+// transaction on it that every later call runs in.
+func (p *PostgresDB) Begin(ctx context.Context) error {
+	if p.pool == nil {
+		return fmt.Errorf("database not connected")
+	}
+
+	p.txMu.Lock()
+	defer p.txMu.Unlock()
+	if p.tx != nil {
+		return fmt.Errorf("a transaction is already open")
+	}
+
+	conn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		conn.Release()
+		return err
+	}
+	p.txConn, p.tx = conn, tx
+	return nil
+}
+
+// Commit implements db.DB.
+func (p *PostgresDB) Commit(ctx context.Context) error {
+	return p.endTx(func(tx pgx.Tx) error { return tx.Commit(ctx) })
+}
+
+// Rollback implements db.DB.
+func (p *PostgresDB) Rollback(ctx context.Context) error {
+	return p.endTx(func(tx pgx.Tx) error { return tx.Rollback(ctx) })
+}
+
+// InTransaction implements db.DB.
+func (p *PostgresDB) InTransaction() bool {
+	p.txMu.Lock()
+	defer p.txMu.Unlock()
+	return p.tx != nil
+}
+
+// endTx finishes the open transaction and hands its connection back. The
+// transaction is over even if finishing fails (a failed COMMIT rolls back).
+func (p *PostgresDB) endTx(finish func(pgx.Tx) error) error {
+	p.txMu.Lock()
+	defer p.txMu.Unlock()
+	if p.tx == nil {
+		return fmt.Errorf("no transaction is open")
+	}
+
+	err := finish(p.tx)
+	p.txConn.Release()
+	p.tx, p.txConn = nil, nil
+	return err
+}
@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// KeyColumns implements db.DB.
+func (p *PostgresDB) KeyColumns(ctx context.Context, table string) ([]string, error) {
+	// partial and expression indexes can't identify a row
+	var key []string
+	err := p.run(ctx, func(q querier) error {
+		err := q.QueryRow(ctx, `
+			SELECT array_agg(a.attname ORDER BY k.ord)
+			FROM pg_index i
+			CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, ord)
+			JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+			WHERE i.indrelid = $1::regclass
+			  AND (i.indisprimary OR i.indisunique)
+			  AND i.indpred IS NULL AND i.indexprs IS NULL
+			GROUP BY i.indexrelid, i.indisprimary
+			HAVING bool_and(a.attnotnull)
+			ORDER BY i.indisprimary DESC, count(*), i.indexrelid
+			LIMIT 1
+		`, db.QuoteIdent(table)).Scan(&key)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// UpdateRow implements db.DB.
+func (p *PostgresDB) UpdateRow(ctx context.Context, table string, key db.RowKey, column string, value any) ([]db.RowImage, error) {
+	if len(key.Columns) == 0 {
+		return nil, fmt.Errorf("table %s has no primary or unique key", table)
+	}
+
+	args := append([]any{value}, key.Values...)
+	var images []db.RowImage
+	err := p.run(ctx, func(q querier) error {
+		rows, err := q.Query(ctx, db.UpdateSQL(table, key, column), args...)
+		if err != nil {
+			return err
+		}
+		images, err = scanImages(rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// InsertRow implements db.DB.
+func (p *PostgresDB) InsertRow(ctx context.Context, table string, columns []string, values []any) (db.RowPage, error) {
+	var (
+		cols []db.Column
+		data [][]db.Value
+	)
+	err := p.run(ctx, func(q querier) error {
+		rows, err := q.Query(ctx, db.InsertSQL(table, columns), values...)
+		if err != nil {
+			return err
+		}
+		cols, data, err = p.readRows(ctx, q, rows, table, db.BinaryHex)
+		return err
+	})
+	if err != nil {
+		return db.RowPage{}, err
+	}
+	return db.RowPage{Columns: cols, Rows: data, TotalRows: len(data)}, nil
+}
+
+// maxParams is the most bind parameters one Postgres statement can take.
+const maxParams = 65535
+
+// DeleteByKeys implements db.DB.
+func (p *PostgresDB) DeleteByKeys(ctx context.Context, table string, keys []db.RowKey) ([]db.RowImage, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	columns := keys[0].Columns
+	if len(keys)*len(columns) > maxParams {
+		return nil, fmt.Errorf("too many rows to delete at once (%d)", len(keys))
+	}
+	args := make([]any, 0, len(keys)*len(columns))
+	for _, k := range keys {
+		args = append(args, k.Values...)
+	}
+
+	var images []db.RowImage
+	err := p.run(ctx, func(q querier) error {
+		tx, err := q.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		// no-op after a successful commit
+		defer tx.Rollback(context.Background())
+
+		rows, err := tx.Query(ctx, db.DeleteKeysSQL(table, columns, len(keys)), args...)
+		if err != nil {
+			return err
+		}
+		images, err = scanImages(rows)
+		if err != nil {
+			return err
+		}
+		if n := len(images); n != len(keys) {
+			return fmt.Errorf("only %d of %d rows still exist; nothing was deleted", n, len(keys))
+		}
+		return tx.Commit(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// RestoreRows implements db.DB.
+func (p *PostgresDB) RestoreRows(ctx context.Context, table string, columns []string, images []db.RowImage) (int64, error) {
+	if len(images) == 0 {
+		return 0, nil
+	}
+
+	var affected int64
+	err := p.run(ctx, func(q querier) error {
+		tag, err := q.Exec(ctx, db.RestoreSQL(table, columns), db.ImageArray(images))
+		affected = tag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// RevertColumn implements db.DB.
+func (p *PostgresDB) RevertColumn(ctx context.Context, table string, key []string, column string, value any, images []db.RowImage) (int64, error) {
+	if len(key) == 0 {
+		return 0, fmt.Errorf("table %s has no primary or unique key", table)
+	}
+
+	var affected int64
+	err := p.run(ctx, func(q querier) error {
+		tx, err := q.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		// no-op after a successful commit
+		defer tx.Rollback(context.Background())
+
+		tag, err := tx.Exec(ctx, db.RevertSQL(table, key, column), db.RevertArgs(key, column, value, images)...)
+		if err != nil {
+			return err
+		}
+		if n := tag.RowsAffected(); n != int64(len(images)) {
+			return fmt.Errorf("only %d of %d rows still exist; nothing was changed", n, len(images))
+		}
+		affected = tag.RowsAffected()
+		return tx.Commit(ctx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// scanImages reads the to_jsonb(...) column returned by a write and closes
+// the rows.
+func scanImages(rows pgx.Rows) ([]db.RowImage, error) {
+	defer rows.Close()
+
+	var images []db.RowImage
+	for rows.Next() {
+		var img string
+		if err := rows.Scan(&img); err != nil {
+			return nil, err
+		}
+		images = append(images, db.RowImage(img))
+	}
+	return images, rows.Err()
+}
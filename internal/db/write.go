@@ -0,0 +1,171 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RowKey identifies a single row by the values of its primary key, or of a
+// unique key over NOT NULL columns.
+type RowKey struct {
+	Columns []string
+	Values  []any // as decoded by the driver (Value.Raw)
+}
+
+// RowImage is a row as it was before a write: the text of a jsonb object
+// of its columns, so values of any type round-trip through their text form.
+type RowImage string
+
+// UpdateSQL is the statement UpdateRow runs: the new value is $1 and the
+// key values follow in order. The row is joined to itself to return its
+// before-image.
+func UpdateSQL(table string, key RowKey, column string) string {
+	var same []string
+	for _, c := range key.Columns {
+		same = append(same, fmt.Sprintf("cur.%s = prev.%[1]s", QuoteIdent(c)))
+	}
+	return fmt.Sprintf("UPDATE %[1]s AS cur SET %[2]s = $1 FROM %[1]s AS prev WHERE %[3]s AND %[4]s RETURNING to_jsonb(prev)",
+		QuoteIdent(table), QuoteIdent(column), keyCondition("prev.", key.Columns, 2), strings.Join(same, " AND "))
+}
+
+// InsertSQL is the statement InsertRow runs, with the values as $1, $2, ...
+func InsertSQL(table string, columns []string) string {
+	if len(columns) == 0 {
+		return fmt.Sprintf("INSERT INTO %s DEFAULT VALUES RETURNING *", QuoteIdent(table))
+	}
+	names := make([]string, len(columns))
+	params := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = QuoteIdent(c)
+		params[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING *", QuoteIdent(table), strings.Join(names, ", "), strings.Join(params, ", "))
+}
+
+// DeleteSQL is the statement DeleteRows runs for a WHERE clause written
+// by the user. It returns the deleted rows as images.
+func DeleteSQL(table, where string) string {
+	return fmt.Sprintf("DELETE FROM %s WHERE %s RETURNING to_jsonb(%[1]s)", QuoteIdent(table), where)
+}
+
+// CopySQL is the statement CopyRows runs, with the rows sent over the
+// COPY protocol.
+func CopySQL(table string, columns []string) string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = QuoteIdent(c)
+	}
+	return fmt.Sprintf("COPY %s (%s) FROM STDIN", QuoteIdent(table), strings.Join(names, ", "))
+}
+
+// DeleteKeysSQL is the statement DeleteByKeys runs for n keys, with the key
+// values as parameters in order. It returns the deleted rows as images.
+func DeleteKeysSQL(table string, columns []string, n int) string {
+	tuples := make([]string, n)
+	for i := range tuples {
+		params := make([]string, len(columns))
+		for j := range columns {
+			params[j] = fmt.Sprintf("$%d", i*len(columns)+j+1)
+		}
+		tuples[i] = strings.Join(params, ", ")
+	}
+
+	if len(columns) == 1 {
+		return fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s) RETURNING to_jsonb(%[1]s)", QuoteIdent(table), QuoteIdent(columns[0]), strings.Join(tuples, ", "))
+	}
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = QuoteIdent(c)
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE (%s) IN ((%s)) RETURNING to_jsonb(%[1]s)", QuoteIdent(table), strings.Join(names, ", "), strings.Join(tuples, "), ("))
+}
+
+// RestoreSQL is the statement RestoreRows runs: it inserts the given
+// columns back from the images, passed as a JSON array in $1. Identity
+// values are restored as they were.
+func RestoreSQL(table string, columns []string) string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = QuoteIdent(c)
+	}
+	list := strings.Join(names, ", ")
+	return fmt.Sprintf("INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT %[2]s FROM jsonb_populate_recordset(NULL::%[1]s, $1)", QuoteIdent(table), list)
+}
+
+// RevertSQL is the statement RevertColumn runs: it sets column back to its
+// value in the images, passed as a JSON array in $1, matching rows by key.
+// When column is part of the key, the rows carry the value it was set to
+// instead, passed as $2. See RevertArgs.
+func RevertSQL(table string, key []string, column string) string {
+	same := make([]string, len(key))
+	for i, c := range key {
+		if c == column {
+			same[i] = fmt.Sprintf("cur.%s = $2", QuoteIdent(c))
+		} else {
+			same[i] = fmt.Sprintf("cur.%s = prev.%[1]s", QuoteIdent(c))
+		}
+	}
+	return fmt.Sprintf("UPDATE %[1]s AS cur SET %[2]s = prev.%[2]s FROM jsonb_populate_recordset(NULL::%[1]s, $1) AS prev WHERE %[3]s",
+		QuoteIdent(table), QuoteIdent(column), strings.Join(same, " AND "))
+}
+
+// RevertArgs are the parameters of RevertSQL; value is what column was set
+// to.
+func RevertArgs(key []string, column string, value any, images []RowImage) []any {
+	args := []any{ImageArray(images)}
+	for _, c := range key {
+		if c == column {
+			args = append(args, value)
+		}
+	}
+	return args
+}
+
+// ImageArray joins images into the JSON array RestoreSQL and RevertSQL take.
+func ImageArray(images []RowImage) string {
+	parts := make([]string, len(images))
+	for i, img := range images {
+		parts[i] = string(img)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// keyCondition renders "a = $n AND b = $n+1" for the key columns, each
+// name prefixed with qualifier.
+func keyCondition(qualifier string, columns []string, first int) string {
+	conds := make([]string, len(columns))
+	for i, c := range columns {
+		conds[i] = fmt.Sprintf("%s%s = $%d", qualifier, QuoteIdent(c), first+i)
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// QuoteIdent double-quotes an identifier unless it is a plain lower-case
+// name that needs no quoting.
+func QuoteIdent(name string) string {
+	plain := name != ""
+	for i, r := range name {
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (i > 0 && (r >= '0' && r <= '9' || r == '$'))) {
+			plain = false
+			break
+		}
+	}
+	if plain && !reserved[name] {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// reserved holds common keywords that must be quoted as identifiers.
+var reserved = map[string]bool{
+	"all": true, "and": true, "any": true, "array": true, "as": true, "asc": true,
+	"case": true, "cast": true, "check": true, "column": true, "constraint": true,
+	"create": true, "default": true, "desc": true, "distinct": true, "do": true,
+	"else": true, "end": true, "except": true, "false": true, "for": true,
+	"foreign": true, "from": true, "grant": true, "group": true, "having": true,
+	"in": true, "into": true, "is": true, "join": true, "limit": true, "not": true,
+	"null": true, "offset": true, "on": true, "or": true, "order": true,
+	"primary": true, "references": true, "select": true, "table": true,
+	"then": true, "to": true, "true": true, "union": true, "unique": true,
+	"user": true, "using": true, "when": true, "where": true, "with": true,
+}
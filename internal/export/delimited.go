@@ -0,0 +1,66 @@
+package export
+
+import (
+	"io"
+	"strings"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// delimitedWriter writes CSV (RFC 4180) or TSV (Postgres COPY text format).
+//
+// CSV: NULL is an empty unquoted field, the empty string is written as "".
+// TSV: NULL is \N, and backslash, tab, newline and carriage return are
+// backslash-escaped, so the file loads back with COPY ... FROM.
+type delimitedWriter struct {
+	w   io.Writer
+	csv bool
+	sep byte
+}
+
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func (d *delimitedWriter) Begin(columns []db.Column) error {
+	fields := make([]string, len(columns))
+	for i, c := range columns {
+		fields[i] = d.field(db.Text(c.Name))
+	}
+	return d.line(fields)
+}
+
+func (d *delimitedWriter) Row(values []db.Value) error {
+	fields := make([]string, len(values))
+	for i, v := range values {
+		fields[i] = d.field(v)
+	}
+	return d.line(fields)
+}
+
+func (d *delimitedWriter) End() error { return nil }
+
+func (d *delimitedWriter) line(fields []string) error {
+	eol := "\n"
+	if d.csv {
+		eol = "\r\n"
+	}
+	_, err := io.WriteString(d.w, strings.Join(fields, string(d.sep))+eol)
+	return err
+}
+
+func (d *delimitedWriter) field(v db.Value) string {
+	if !d.csv {
+		if v.Null {
+			return `\N`
+		}
+		return tsvEscaper.Replace(Text(v))
+	}
+
+	if v.Null {
+		return ""
+	}
+	s := Text(v)
+	if s == "" || strings.ContainsAny(s, "\",\r\n") || s[0] == ' ' || s[len(s)-1] == ' ' {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return s
+}
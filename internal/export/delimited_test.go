@@ -0,0 +1,72 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// bytea is a binary cell whose grid text is not its hex form.
+var bytea = db.Value{Raw: []byte{0xde, 0xad, 0x00}, Display: "3q0A"}
+
+func TestDelimitedField(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        db.Value
+		csv, tsv string
+	}{
+		{"plain", db.Text("abc"), "abc", "abc"},
+		{"delimiter", db.Text("a,b\tc"), `"a,b	c"`, `a,b\tc`},
+		{"quote", db.Text(`say "hi"`), `"say ""hi"""`, `say "hi"`},
+		{"newline", db.Text("one\ntwo"), "\"one\ntwo\"", `one\ntwo`},
+		{"carriage return", db.Text("one\r\ntwo"), "\"one\r\ntwo\"", `one\r\ntwo`},
+		{"backslash", db.Text(`C:\dir`), `C:\dir`, `C:\\dir`},
+		{"edge spaces", db.Text(" pad "), `" pad "`, " pad "},
+		{"empty string", db.Text(""), `""`, ""},
+		{"NULL", db.NullValue, "", `\N`},
+		{"NULL as text", db.Text(`\N`), `\N`, `\\N`},
+		{"number", db.Value{Raw: int64(-42), Display: "-42"}, "-42", "-42"},
+		{"text that looks numeric", db.Text("007"), "007", "007"},
+		{"bytea", bytea, `\xdead00`, `\\xdead00`},
+		{"json", db.Value{Raw: "{\"a\": [1,\n 2]}", Display: `{"a":[1,2]}`}, "\"{\"\"a\"\": [1,\n 2]}\"", `{"a": [1,\n 2]}`},
+	}
+
+	csv := &delimitedWriter{csv: true, sep: ','}
+	tsv := &delimitedWriter{sep: '\t'}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csv.field(tt.v); got != tt.csv {
+				t.Errorf("CSV field = %q, want %q", got, tt.csv)
+			}
+			if got := tsv.field(tt.v); got != tt.tsv {
+				t.Errorf("TSV field = %q, want %q", got, tt.tsv)
+			}
+		})
+	}
+}
+
+func TestDelimitedRows(t *testing.T) {
+	columns := []db.Column{{Name: "id"}, {Name: "a,b"}}
+	rows := [][]db.Value{
+		{db.Text("1"), db.NullValue},
+		{db.Text("2"), db.Text("")},
+	}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{CSV, "id,\"a,b\"\r\n1,\r\n2,\"\"\r\n"},
+		{TSV, "id\ta,b\n1\t\\N\n2\t\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			got, err := String(tt.format, Options{}, columns, rows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package export
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// Format of an export.
+type Format int
+
+const (
+	CSV Format = iota
+	TSV
+	JSON
+	NDJSON
+	SQL
+	Markdown
+	HTML
+)
+
+// Formats lists every format in the order the UI cycles through them.
+var Formats = []Format{CSV, TSV, JSON, NDJSON, SQL, Markdown, HTML}
+
+func (f Format) String() string {
+	switch f {
+	case TSV:
+		return "TSV"
+	case JSON:
+		return "JSON"
+	case NDJSON:
+		return "NDJSON"
+	case SQL:
+		return "SQL"
+	case Markdown:
+		return "Markdown"
+	case HTML:
+		return "HTML"
+	}
+	return "CSV"
+}
+
+// Ext is the file extension for the format, with the dot.
+func (f Format) Ext() string {
+	if f == Markdown {
+		return ".md"
+	}
+	return "." + strings.ToLower(f.String())
+}
+
+// FormatForPath picks the format from a file name's extension.
+func FormatForPath(path string) (Format, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, f := range Formats {
+		if f.Ext() == ext {
+			return f, true
+		}
+	}
+	return CSV, false
+}
+
+// Writer streams a result set: Begin once, Row for each row, then End.
+type Writer interface {
+	Begin(columns []db.Column) error
+	Row(values []db.Value) error
+	End() error
+}
+
+// Options for formats that need more than the rows themselves.
+type Options struct {
+	// SQL, and the HTML page title
+	Schema              string
+	Table               string
+	TableColumns        []db.Column // SQL: the table's own columns, to leave out generated ones
+	MultiRow            bool        // one INSERT per batch of rows instead of per row
+	OnConflictDoNothing bool
+}
+
+// Text is the data of a non-NULL value as text: strings as stored, binary
+// data as \x hex whatever the grid shows, and anything else in its psql
+// text form.
+func Text(v db.Value) string {
+	switch raw := v.Raw.(type) {
+	case string:
+		return raw
+	case []byte:
+		return `\x` + hex.EncodeToString(raw)
+	}
+	return v.Display
+}
+
+// New returns a writer for the format.
+func New(f Format, w io.Writer, opts Options) (Writer, error) {
+	switch f {
+	case CSV:
+		return &delimitedWriter{w: w, csv: true, sep: ','}, nil
+	case TSV:
+		return &delimitedWriter{w: w, sep: '\t'}, nil
+	case JSON:
+		return &jsonWriter{w: w, array: true}, nil
+	case NDJSON:
+		return &jsonWriter{w: w}, nil
+	case SQL:
+		if opts.Table == "" {
+			return nil, fmt.Errorf("SQL export needs a table name")
+		}
+		return &sqlWriter{w: w, opts: opts}, nil
+	case Markdown:
+		return &markdownWriter{w: w}, nil
+	case HTML:
+		return &htmlWriter{w: w, title: opts.Table}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format %v", f)
+}
+
+// WriteAll writes a complete result set in one go.
+func WriteAll(wr Writer, columns []db.Column, rows [][]db.Value) error {
+	if err := wr.Begin(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := wr.Row(row); err != nil {
+			return err
+		}
+	}
+	return wr.End()
+}
+
+// String renders a result set in the format, for when the output goes
+// somewhere other than a file.
+func String(f Format, opts Options, columns []db.Column, rows [][]db.Value) (string, error) {
+	var sb strings.Builder
+	wr, err := New(f, &sb, opts)
+	if err != nil {
+		return "", err
+	}
+	if err := WriteAll(wr, columns, rows); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
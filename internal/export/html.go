@@ -0,0 +1,87 @@
+package export
+
+import (
+	"html"
+	"io"
+	"strings"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// htmlHead starts a standalone page; the table is readable without any
+// external stylesheet.
+const htmlHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%TITLE%</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; vertical-align: top; white-space: pre-wrap; }
+th { background: #f3f3f3; text-align: left; }
+tbody tr:nth-child(even) { background: #fafafa; }
+td.num { text-align: right; }
+td.null { color: #999; font-style: italic; }
+</style>
+</head>
+<body>
+<table>
+`
+
+const htmlFoot = `</tbody>
+</table>
+</body>
+</html>
+`
+
+// htmlWriter writes a standalone HTML document with one table. Cells hold
+// the original text, escaped, with newlines kept.
+type htmlWriter struct {
+	w       io.Writer
+	title   string
+	columns []db.Column
+}
+
+func (h *htmlWriter) Begin(columns []db.Column) error {
+	h.columns = columns
+
+	var sb strings.Builder
+	sb.WriteString(strings.Replace(htmlHead, "%TITLE%", html.EscapeString(h.title), 1))
+	sb.WriteString("<thead>\n<tr>")
+	for _, c := range columns {
+		sb.WriteString("<th>" + html.EscapeString(c.Name) + "</th>")
+	}
+	sb.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	_, err := io.WriteString(h.w, sb.String())
+	return err
+}
+
+func (h *htmlWriter) Row(values []db.Value) error {
+	var sb strings.Builder
+	sb.WriteString("<tr>")
+	for i, c := range h.columns {
+		var v db.Value
+		if i < len(values) {
+			v = values[i]
+		}
+		switch {
+		case v.Null:
+			sb.WriteString(`<td class="null">NULL</td>`)
+		case c.Class == db.ClassNumeric:
+			sb.WriteString(`<td class="num">` + html.EscapeString(Text(v)) + "</td>")
+		default:
+			sb.WriteString("<td>" + html.EscapeString(Text(v)) + "</td>")
+		}
+	}
+	sb.WriteString("</tr>\n")
+
+	_, err := io.WriteString(h.w, sb.String())
+	return err
+}
+
+func (h *htmlWriter) End() error {
+	_, err := io.WriteString(h.w, htmlFoot)
+	return err
+}
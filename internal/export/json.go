@@ -0,0 +1,117 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// jsonWriter writes one object per row with keys in column order, either
+// as a JSON array or as newline-delimited JSON. Values keep their type:
+// numbers and booleans are unquoted, json/jsonb is embedded compacted and NULL
+// becomes null.
+type jsonWriter struct {
+	w       io.Writer
+	array   bool
+	columns []db.Column
+	rows    int
+}
+
+func (j *jsonWriter) Begin(columns []db.Column) error {
+	j.columns = columns
+	if j.array {
+		_, err := io.WriteString(j.w, "[")
+		return err
+	}
+	return nil
+}
+
+func (j *jsonWriter) Row(values []db.Value) error {
+	var sb strings.Builder
+	switch {
+	case j.array && j.rows > 0:
+		sb.WriteString(",\n  ")
+	case j.array:
+		sb.WriteString("\n  ")
+	}
+	sb.WriteString(Object(j.columns, values))
+	if !j.array {
+		sb.WriteString("\n")
+	}
+	j.rows++
+
+	_, err := io.WriteString(j.w, sb.String())
+	return err
+}
+
+func (j *jsonWriter) End() error {
+	if !j.array {
+		return nil
+	}
+	end := "]\n"
+	if j.rows > 0 {
+		end = "\n]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+// Object encodes one row as a JSON object with keys in column order.
+func Object(columns []db.Column, values []db.Value) string {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, c := range columns {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(jsonString(c.Name))
+		sb.WriteString(":")
+		if i < len(values) {
+			sb.WriteString(Value(c, values[i]))
+		} else {
+			sb.WriteString("null")
+		}
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// Value encodes a single cell as JSON according to its column's type class.
+func Value(c db.Column, v db.Value) string {
+	if v.Null {
+		return "null"
+	}
+	text := Text(v)
+	switch c.Class {
+	case db.ClassNumeric:
+		// NaN and Infinity have no JSON number form
+		if json.Valid([]byte(text)) && !strings.ContainsAny(text, `"{}[]`) {
+			return text
+		}
+	case db.ClassBool:
+		switch text {
+		case "t", "true":
+			return "true"
+		case "f", "false":
+			return "false"
+		}
+	case db.ClassJSON:
+		// on one line, so NDJSON keeps a row per line
+		var b bytes.Buffer
+		if json.Compact(&b, []byte(text)) == nil {
+			return b.String()
+		}
+	}
+	return jsonString(text)
+}
+
+func jsonString(s string) string {
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
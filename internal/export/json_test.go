@@ -0,0 +1,82 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+func TestJSONValue(t *testing.T) {
+	var (
+		numeric = db.Column{Name: "n", Class: db.ClassNumeric}
+		text    = db.Column{Name: "s", Class: db.ClassText}
+		boolean = db.Column{Name: "b", Class: db.ClassBool}
+		doc     = db.Column{Name: "j", Class: db.ClassJSON}
+		binary  = db.Column{Name: "x", Class: db.ClassBinary}
+	)
+	tests := []struct {
+		name string
+		c    db.Column
+		v    db.Value
+		want string
+	}{
+		{"integer", numeric, db.Value{Raw: int64(42), Display: "42"}, "42"},
+		{"numeric keeps digits", numeric, db.Text("12345678901234567890.10"), "12345678901234567890.10"},
+		{"float exponent", numeric, db.Value{Raw: 1e+15, Display: "1e+15"}, "1e+15"},
+		{"NaN", numeric, db.Text("NaN"), `"NaN"`},
+		{"infinity", numeric, db.Value{Raw: -1.0, Display: "-Infinity"}, `"-Infinity"`},
+		{"text that looks numeric", text, db.Text("42"), `"42"`},
+		{"text escapes", text, db.Text("a \"q\"\n<b> & \\"), `"a \"q\"\n<b> & \\"`},
+		{"empty string", text, db.Text(""), `""`},
+		{"NULL", text, db.NullValue, "null"},
+		{"true", boolean, db.Value{Raw: true, Display: "t"}, "true"},
+		{"false", boolean, db.Value{Raw: false, Display: "f"}, "false"},
+		{"json embedded", doc, db.Text(`{"b":1,"a":[true,null]}`), `{"b":1,"a":[true,null]}`},
+		{"json on one line", doc, db.Text("{\n  \"a\": 1,\n  \"a\": 2\n}"), `{"a":1,"a":2}`},
+		{"json scalar string", doc, db.Text(`"x"`), `"x"`},
+		{"bytea", binary, bytea, `"\\xdead00"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Value(tt.c, tt.v); got != tt.want {
+				t.Errorf("Value(%v, %q) = %s, want %s", tt.c.Class, tt.v.Display, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONRows(t *testing.T) {
+	columns := []db.Column{{Name: "id", Class: db.ClassNumeric}, {Name: "doc", Class: db.ClassJSON}}
+	rows := [][]db.Value{
+		{db.Text("1"), db.Text("{\n  \"a\": 1\n}")},
+		{db.Text("2"), db.NullValue},
+	}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSON, "[\n  {\"id\":1,\"doc\":{\"a\":1}},\n  {\"id\":2,\"doc\":null}\n]\n"},
+		{NDJSON, "{\"id\":1,\"doc\":{\"a\":1}}\n{\"id\":2,\"doc\":null}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			got, err := String(tt.format, Options{}, columns, rows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no rows", func(t *testing.T) {
+		got, err := String(JSON, Options{}, columns, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "[]\n"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+}
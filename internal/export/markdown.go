@@ -0,0 +1,31 @@
+package export
+
+import (
+	"io"
+
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/ui/table"
+)
+
+// markdownWriter writes a GitHub-flavored Markdown table. Column widths
+// depend on every row, so rows are buffered until End.
+type markdownWriter struct {
+	w       io.Writer
+	columns []db.Column
+	rows    [][]db.Value
+}
+
+func (m *markdownWriter) Begin(columns []db.Column) error {
+	m.columns = columns
+	return nil
+}
+
+func (m *markdownWriter) Row(values []db.Value) error {
+	m.rows = append(m.rows, values)
+	return nil
+}
+
+func (m *markdownWriter) End() error {
+	_, err := io.WriteString(m.w, table.Markdown(m.columns, m.rows))
+	return err
+}
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"io"
+	"strings"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// multiRowBatch is how many rows go into one multi-row INSERT.
+const multiRowBatch = 100
+
+// sqlWriter writes INSERT statements that recreate the rows.
+type sqlWriter struct {
+	w       io.Writer
+	opts    Options
+	target  string // INSERT INTO schema.table (cols)
+	columns []db.Column
+	keep    []int    // columns written; generated ones can't be inserted
+	pending []string // VALUES tuples not written yet
+}
+
+func (s *sqlWriter) Begin(columns []db.Column) error {
+	s.columns = columns
+
+	table := make(map[string]db.Column, len(s.opts.TableColumns))
+	for _, c := range s.opts.TableColumns {
+		table[c.Name] = c
+	}
+
+	var (
+		names    []string
+		identity bool
+	)
+	s.keep = nil
+	for i, c := range columns {
+		if table[c.Name].Generated {
+			continue
+		}
+		identity = identity || table[c.Name].Default == "identity"
+		s.keep = append(s.keep, i)
+		names = append(names, db.QuoteIdent(c.Name))
+	}
+
+	target := db.QuoteIdent(s.opts.Table)
+	if s.opts.Schema != "" {
+		target = db.QuoteIdent(s.opts.Schema) + "." + target
+	}
+	s.target = "INSERT INTO " + target + " (" + strings.Join(names, ", ") + ")"
+	// GENERATED ALWAYS identities only take the exported value this way
+	if identity {
+		s.target += " OVERRIDING SYSTEM VALUE"
+	}
+	return nil
+}
+
+func (s *sqlWriter) Row(values []db.Value) error {
+	literals := make([]string, len(s.keep))
+	for j, i := range s.keep {
+		if i < len(values) {
+			literals[j] = Literal(s.columns[i], values[i])
+		} else {
+			literals[j] = "NULL"
+		}
+	}
+	s.pending = append(s.pending, "("+strings.Join(literals, ", ")+")")
+
+	if !s.opts.MultiRow || len(s.pending) >= multiRowBatch {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *sqlWriter) End() error {
+	return s.flush()
+}
+
+func (s *sqlWriter) flush() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(s.target)
+	if len(s.pending) == 1 {
+		sb.WriteString(" VALUES ")
+		sb.WriteString(s.pending[0])
+	} else {
+		sb.WriteString(" VALUES\n  ")
+		sb.WriteString(strings.Join(s.pending, ",\n  "))
+	}
+	if s.opts.OnConflictDoNothing {
+		sb.WriteString(" ON CONFLICT DO NOTHING")
+	}
+	sb.WriteString(";\n")
+	s.pending = s.pending[:0]
+
+	_, err := io.WriteString(s.w, sb.String())
+	return err
+}
+
+// Literal renders a value as a SQL literal for its column. Numbers and
+// booleans are written bare; everything else is a quoted string that
+// Postgres coerces to the column type on INSERT.
+func Literal(c db.Column, v db.Value) string {
+	if v.Null {
+		return "NULL"
+	}
+
+	text := Text(v)
+	switch c.Class {
+	case db.ClassNumeric:
+		if isNumber(text) {
+			return text
+		}
+	case db.ClassBool:
+		switch text {
+		case "t", "true":
+			return "TRUE"
+		case "f", "false":
+			return "FALSE"
+		}
+	}
+	return QuoteLiteral(text)
+}
+
+// QuoteLiteral single-quotes a string, doubling embedded quotes.
+func QuoteLiteral(s string) string {
+	return db.QuoteLiteral(s)
+}
+
+// isNumber reports whether s is a plain decimal or exponent number.
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	digits, dot, exp := false, false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case c == '-' || c == '+':
+			if i != 0 && s[i-1] != 'e' && s[i-1] != 'E' {
+				return false
+			}
+		case c == '.':
+			if dot || exp {
+				return false
+			}
+			dot = true
+		case c == 'e' || c == 'E':
+			if exp || !digits {
+				return false
+			}
+			// the exponent needs digits of its own
+			exp, digits = true, false
+		default:
+			return false
+		}
+	}
+	return digits
+}
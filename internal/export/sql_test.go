@@ -0,0 +1,123 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+func TestLiteral(t *testing.T) {
+	var (
+		numeric = db.Column{Name: "n", Class: db.ClassNumeric}
+		text    = db.Column{Name: "s", Class: db.ClassText}
+		boolean = db.Column{Name: "b", Class: db.ClassBool}
+		doc     = db.Column{Name: "j", Class: db.ClassJSON}
+		binary  = db.Column{Name: "x", Class: db.ClassBinary}
+	)
+	tests := []struct {
+		name string
+		c    db.Column
+		v    db.Value
+		want string
+	}{
+		{"integer", numeric, db.Value{Raw: int64(-42), Display: "-42"}, "-42"},
+		{"numeric keeps digits", numeric, db.Text("12345678901234567890.10"), "12345678901234567890.10"},
+		{"float exponent", numeric, db.Value{Raw: 1e-05, Display: "1e-05"}, "1e-05"},
+		{"NaN", numeric, db.Text("NaN"), "'NaN'"},
+		{"infinity", numeric, db.Text("Infinity"), "'Infinity'"},
+		{"text that looks numeric", text, db.Text("007"), "'007'"},
+		{"quote", text, db.Text("O'Brien"), "'O''Brien'"},
+		{"backslash and newline", text, db.Text("a\\b\nc"), "'a\\b\nc'"},
+		{"empty string", text, db.Text(""), "''"},
+		{"NULL", text, db.NullValue, "NULL"},
+		{"true", boolean, db.Value{Raw: true, Display: "t"}, "TRUE"},
+		{"false", boolean, db.Value{Raw: false, Display: "f"}, "FALSE"},
+		{"json as sent", doc, db.Value{Raw: `{"a": 1, "a": 2}`, Display: `{"a":1,"a":2}`}, `'{"a": 1, "a": 2}'`},
+		{"bytea", binary, bytea, `'\xdead00'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Literal(tt.c, tt.v); got != tt.want {
+				t.Errorf("Literal(%v, %q) = %s, want %s", tt.c.Class, tt.v.Display, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNumber(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"0", true},
+		{"-12", true},
+		{"+3.5", true},
+		{".5", true},
+		{"5.", true},
+		{"1e10", true},
+		{"1.5E-3", true},
+		{"", false},
+		{"-", false},
+		{".", false},
+		{"1e", false},
+		{"1e+", false},
+		{"e5", false},
+		{"1.2.3", false},
+		{"1e5.0", false},
+		{"1-2", false},
+		{"0x1F", false},
+		{"1 000", false},
+		{"NaN", false},
+	}
+	for _, tt := range tests {
+		if got := isNumber(tt.s); got != tt.want {
+			t.Errorf("isNumber(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestSQLWriter(t *testing.T) {
+	columns := []db.Column{
+		{Name: "id", Class: db.ClassNumeric},
+		{Name: "name", Class: db.ClassText},
+		{Name: "total", Class: db.ClassNumeric},
+	}
+	table := []db.Column{
+		{Name: "id", Default: "identity"},
+		{Name: "name"},
+		{Name: "total", Generated: true},
+	}
+	rows := [][]db.Value{
+		{db.Text("1"), db.Text("a"), db.Text("10")},
+		{db.Text("2"), db.NullValue, db.Text("20")},
+	}
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"one per row", Options{Table: "users"},
+			"INSERT INTO users (id, name, total) VALUES (1, 'a', 10);\n" +
+				"INSERT INTO users (id, name, total) VALUES (2, NULL, 20);\n"},
+		{"generated and identity", Options{Table: "users", TableColumns: table},
+			"INSERT INTO users (id, name) OVERRIDING SYSTEM VALUE VALUES (1, 'a');\n" +
+				"INSERT INTO users (id, name) OVERRIDING SYSTEM VALUE VALUES (2, NULL);\n"},
+		{"multi-row", Options{Schema: "Sales", Table: "order", MultiRow: true, OnConflictDoNothing: true},
+			"INSERT INTO \"Sales\".\"order\" (id, name, total) VALUES\n  (1, 'a', 10),\n  (2, NULL, 20) ON CONFLICT DO NOTHING;\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := String(SQL, tt.opts, columns, rows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := New(SQL, nil, Options{}); err == nil {
+		t.Error("New(SQL) without a table name succeeded")
+	}
+}
@@ -0,0 +1,223 @@
+package jsonview
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// step is one segment of a path expression.
+type step struct {
+	key     string
+	index   int
+	isIndex bool
+	iterate bool // []
+}
+
+// Path is a compiled jq-style path expression such as `.a.b[0]`,
+// `.["odd key"]` or `.items[].id`.
+type Path struct {
+	src   string
+	steps []step
+}
+
+func (p Path) String() string { return p.src }
+
+// CompilePath parses a jq-style path expression.
+func CompilePath(expr string) (Path, error) {
+	src := strings.TrimSpace(expr)
+	if src == "" || src[0] != '.' {
+		return Path{}, fmt.Errorf("path must start with '.'")
+	}
+
+	var steps []step
+	s := src
+	for len(s) > 0 {
+		switch {
+		case s == ".":
+			s = ""
+
+		case s[0] == '.' && len(s) > 1 && s[1] == '[':
+			s = s[1:]
+
+		case s[0] == '.' && len(s) > 1 && s[1] == '"':
+			key, rest, err := readQuoted(s[1:])
+			if err != nil {
+				return Path{}, err
+			}
+			steps = append(steps, step{key: key})
+			s = rest
+
+		case s[0] == '.':
+			i := 1
+			for i < len(s) && isIdentByte(s[i], i == 1) {
+				i++
+			}
+			if i == 1 {
+				return Path{}, fmt.Errorf("unexpected %q in path", s[1:2])
+			}
+			steps = append(steps, step{key: s[1:i]})
+			s = s[i:]
+
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return Path{}, fmt.Errorf("missing ']' in path")
+			}
+			inner := strings.TrimSpace(s[1:end])
+			switch {
+			case inner == "":
+				steps = append(steps, step{iterate: true})
+				s = s[end+1:]
+			case inner[0] == '"':
+				key, rest, err := readQuoted(strings.TrimSpace(s[1:]))
+				if err != nil {
+					return Path{}, err
+				}
+				rest = strings.TrimSpace(rest)
+				if !strings.HasPrefix(rest, "]") {
+					return Path{}, fmt.Errorf("missing ']' in path")
+				}
+				steps = append(steps, step{key: key})
+				s = rest[1:]
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return Path{}, fmt.Errorf("invalid index %q", inner)
+				}
+				steps = append(steps, step{index: n, isIndex: true})
+				s = s[end+1:]
+			}
+
+		default:
+			return Path{}, fmt.Errorf("unexpected %q in path", s[:1])
+		}
+	}
+
+	return Path{src: src, steps: steps}, nil
+}
+
+func isIdentByte(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
+
+func readQuoted(s string) (string, string, error) {
+	if len(s) == 0 || s[0] != '"' {
+		return "", "", fmt.Errorf("expected quoted key")
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			key, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid quoted key %s", s[:i+1])
+			}
+			return key, s[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quoted key")
+}
+
+// Eval applies the path to a JSON document and returns each result as
+// compact JSON. Like jq, missing keys and out-of-range indexes yield null.
+func (p Path) Eval(doc string) ([]string, error) {
+	dec := json.NewDecoder(strings.NewReader(doc))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	cur := []any{v}
+	for _, st := range p.steps {
+		var next []any
+		for _, c := range cur {
+			res, err := st.apply(c)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, res...)
+		}
+		cur = next
+	}
+
+	out := make([]string, len(cur))
+	for i, c := range cur {
+		out[i] = Compact(c)
+	}
+	return out, nil
+}
+
+func (st step) apply(v any) ([]any, error) {
+	if v == nil {
+		if st.iterate {
+			return nil, fmt.Errorf("cannot iterate over null")
+		}
+		return []any{nil}, nil
+	}
+
+	switch {
+	case st.iterate:
+		switch t := v.(type) {
+		case []any:
+			return t, nil
+		case map[string]any:
+			keys := make([]string, 0, len(t))
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			out := make([]any, 0, len(t))
+			for _, k := range keys {
+				out = append(out, t[k])
+			}
+			return out, nil
+		}
+		return nil, fmt.Errorf("cannot iterate over %s", typeName(v))
+
+	case st.isIndex:
+		arr, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot index %s with number", typeName(v))
+		}
+		i := st.index
+		if i < 0 {
+			i += len(arr)
+		}
+		if i < 0 || i >= len(arr) {
+			return []any{nil}, nil
+		}
+		return []any{arr[i]}, nil
+
+	default:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot index %s with %q", typeName(v), st.key)
+		}
+		return []any{obj[st.key]}, nil
+	}
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
@@ -0,0 +1,112 @@
+package jsonview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles used for syntax highlighting.
+type Styles struct {
+	Key    lipgloss.Style
+	String lipgloss.Style
+	Number lipgloss.Style
+	Bool   lipgloss.Style
+	Null   lipgloss.Style
+	Punct  lipgloss.Style
+	Fold   lipgloss.Style
+}
+
+// DefaultStyles work on most dark and light terminals.
+var DefaultStyles = Styles{
+	Key:    lipgloss.NewStyle().Foreground(lipgloss.Color("12")),
+	String: lipgloss.NewStyle().Foreground(lipgloss.Color("10")),
+	Number: lipgloss.NewStyle().Foreground(lipgloss.Color("14")),
+	Bool:   lipgloss.NewStyle().Foreground(lipgloss.Color("11")),
+	Null:   lipgloss.NewStyle().Foreground(lipgloss.Color("8")),
+	Punct:  lipgloss.NewStyle(),
+	Fold:   lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Italic(true),
+}
+
+// Line is one rendered line of the pretty-printed document. Node is the
+// container that starts on this line (nil for scalars and closing brackets),
+// so the caller can toggle it.
+type Line struct {
+	Text string
+	Node *Node
+}
+
+// Lines pretty-prints the tree with two-space indentation, honoring the
+// Collapsed flag of each container.
+func Lines(root *Node, st Styles) []Line {
+	var out []Line
+	st.writeNode(&out, root, 0, false)
+	return out
+}
+
+func (st Styles) writeNode(out *[]Line, n *Node, depth int, comma bool) {
+	indent := strings.Repeat("  ", depth)
+	prefix := indent
+	if n.HasKey {
+		prefix += st.Key.Render(quote(n.Key)) + st.Punct.Render(": ")
+	}
+	trail := ""
+	if comma {
+		trail = st.Punct.Render(",")
+	}
+
+	switch n.Kind {
+	case KindObject, KindArray:
+		open, closing := "{", "}"
+		if n.Kind == KindArray {
+			open, closing = "[", "]"
+		}
+
+		if len(n.Children) == 0 {
+			*out = append(*out, Line{Text: prefix + st.Punct.Render(open+closing) + trail})
+			return
+		}
+
+		if n.Collapsed {
+			*out = append(*out, Line{
+				Text: prefix + st.Punct.Render(open) + st.Fold.Render(foldSummary(n)) + st.Punct.Render(closing) + trail,
+				Node: n,
+			})
+			return
+		}
+
+		*out = append(*out, Line{Text: prefix + st.Punct.Render(open), Node: n})
+		for i, c := range n.Children {
+			st.writeNode(out, c, depth+1, i < len(n.Children)-1)
+		}
+		*out = append(*out, Line{Text: indent + st.Punct.Render(closing) + trail})
+
+	default:
+		*out = append(*out, Line{Text: prefix + st.scalar(n) + trail})
+	}
+}
+
+func foldSummary(n *Node) string {
+	unit := "items"
+	if n.Kind == KindObject {
+		unit = "keys"
+	}
+	if len(n.Children) == 1 {
+		unit = strings.TrimSuffix(unit, "s")
+	}
+	return fmt.Sprintf(" … %d %s ", len(n.Children), unit)
+}
+
+func (st Styles) scalar(n *Node) string {
+	switch n.Kind {
+	case KindString:
+		return st.String.Render(n.Literal)
+	case KindNumber:
+		return st.Number.Render(n.Literal)
+	case KindBool:
+		return st.Bool.Render(n.Literal)
+	default:
+		return st.Null.Render(n.Literal)
+	}
+}
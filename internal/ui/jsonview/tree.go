@@ -0,0 +1,139 @@
+package jsonview
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Kind of a JSON node.
+type Kind int
+
+const (
+	KindNull Kind = iota
+	KindBool
+	KindNumber
+	KindString
+	KindArray
+	KindObject
+)
+
+// Node is one value in a parsed JSON document. Object keys keep the order
+// they had in the source text.
+type Node struct {
+	Kind      Kind
+	Key       string // object key when the parent is an object
+	HasKey    bool
+	Literal   string // JSON-encoded scalar (numbers, strings, bools, null)
+	Children  []*Node
+	Collapsed bool
+}
+
+// Parse builds a tree from a JSON document.
+func Parse(s string) (*Node, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	n, err := parseValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return n, nil
+}
+
+func parseValue(dec *json.Decoder) (*Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			n := &Node{Kind: KindObject}
+			for dec.More() {
+				kt, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := kt.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid object key %v", kt)
+				}
+				child, err := parseValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				child.Key = key
+				child.HasKey = true
+				n.Children = append(n.Children, child)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return n, nil
+		case '[':
+			n := &Node{Kind: KindArray}
+			for dec.More() {
+				child, err := parseValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.Children = append(n.Children, child)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return n, nil
+		}
+		return nil, fmt.Errorf("unexpected delimiter %v", t)
+
+	case nil:
+		return &Node{Kind: KindNull, Literal: "null"}, nil
+	case bool:
+		return &Node{Kind: KindBool, Literal: fmt.Sprint(t)}, nil
+	case json.Number:
+		return &Node{Kind: KindNumber, Literal: t.String()}, nil
+	case string:
+		return &Node{Kind: KindString, Literal: quote(t)}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected token %v", tok)
+}
+
+// quote JSON-encodes a string without escaping <, > and &.
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// SetCollapsed collapses or expands every container below (and including) n.
+func (n *Node) SetCollapsed(collapsed bool) {
+	if n.Kind != KindObject && n.Kind != KindArray {
+		return
+	}
+	n.Collapsed = collapsed
+	for _, c := range n.Children {
+		c.SetCollapsed(collapsed)
+	}
+}
+
+// Compact re-encodes v as single-line JSON.
+func Compact(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
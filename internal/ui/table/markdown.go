@@ -0,0 +1,60 @@
+package table
+
+import (
+	"strings"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+// markdownEscaper keeps cell text inside its column and out of inline HTML.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "<", "&lt;")
+
+// Markdown builds a GitHub-flavored Markdown table with the same cell text
+// and column widths as Render, so the source reads well unrendered too.
+// Numeric columns are right-aligned.
+func Markdown(columns []db.Column, rows [][]db.Value) string {
+	if len(columns) == 0 {
+		return ""
+	}
+
+	headers, cells := gridText(columns, rows)
+	for i := range headers {
+		headers[i] = markdownEscaper.Replace(headers[i])
+	}
+	for _, row := range cells {
+		for i := range row {
+			row[i] = markdownEscaper.Replace(row[i])
+		}
+	}
+	widths := contentWidths(headers, cells)
+	for i := range widths {
+		// room for the ---: alignment row
+		widths[i] = max(widths[i], 3)
+	}
+
+	var sb strings.Builder
+	writeRow := func(row []string) {
+		sb.WriteString("|")
+		for i, col := range columns {
+			sb.WriteString(" ")
+			sb.WriteString(pad(row[i], widths[i], col.Class == db.ClassNumeric))
+			sb.WriteString(" |")
+		}
+		sb.WriteString("\n")
+	}
+
+	writeRow(headers)
+	sb.WriteString("|")
+	for i, col := range columns {
+		if col.Class == db.ClassNumeric {
+			sb.WriteString(" " + strings.Repeat("-", widths[i]-1) + ": |")
+		} else {
+			sb.WriteString(" " + strings.Repeat("-", widths[i]) + " |")
+		}
+	}
+	sb.WriteString("\n")
+	for _, row := range cells {
+		writeRow(row)
+	}
+	return sb.String()
+}
@@ -3,42 +3,149 @@ package table
 import (
 	"fmt"
 	"strings"
+	"unicode"
 	"unicode/utf8"
+
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
 )
 
-// Render builds an ASCII table from columns + rows.
-func Render(columns []string, rows [][]string) string {
-	if len(columns) == 0 {
-		return "(No columns)\n"
+// NullText is how SQL NULL is shown, so it can't be confused with the
+// string "NULL".
+const NullText = "∅"
+
+// cellEscaper keeps a cell on a single grid line.
+var cellEscaper = strings.NewReplacer("\r\n", `\r\n`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// Width returns the number of terminal columns s occupies.
+func Width(s string) int {
+	return runewidth.StringWidth(s)
+}
+
+// pad fills s with spaces up to w display columns, on the right or left.
+func pad(s string, w int, right bool) string {
+	n := w - Width(s)
+	if n <= 0 {
+		return s
+	}
+	if right {
+		return strings.Repeat(" ", n) + s
 	}
+	return s + strings.Repeat(" ", n)
+}
+
+// CellText returns the text shown for a cell, safe to print to a terminal.
+func CellText(v db.Value) string {
+	if v.Null {
+		return NullText
+	}
+	return escapeControl(v.Display)
+}
 
-	// Calculate width of each column
-	widths := make([]int, len(columns))
+// escapeControl makes text safe to print to a terminal: control characters
+// other than newline and tab, and invalid UTF-8 bytes, are shown as escapes
+// instead of being interpreted.
+func escapeControl(s string) string {
+	clean := true
+	for _, r := range s {
+		if r == utf8.RuneError || (unicode.IsControl(r) && r != '\n' && r != '\t') {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			fmt.Fprintf(&sb, `\x%02x`, s[i])
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\n' || r == '\t':
+			sb.WriteRune(r)
+		case r < 0x80 && unicode.IsControl(r):
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+// gridText returns the escaped header and cell strings of a page.
+func gridText(columns []db.Column, rows [][]db.Value) ([]string, [][]string) {
+	headers := make([]string, len(columns))
 	for i, col := range columns {
-		widths[i] = utf8.RuneCountInString(col)
+		headers[i] = cellEscaper.Replace(col.Name)
 	}
-	for _, row := range rows {
-		for i, cell := range row {
-			if i >= len(widths) {
-				continue
+	cells := make([][]string, len(rows))
+	for r, row := range rows {
+		cells[r] = make([]string, len(columns))
+		for i := range columns {
+			if i < len(row) {
+				cells[r][i] = cellEscaper.Replace(CellText(row[i]))
 			}
-			l := utf8.RuneCountInString(cell)
-			if l > widths[i] {
+		}
+	}
+	return headers, cells
+}
+
+func contentWidths(headers []string, cells [][]string) []int {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = Width(h)
+	}
+	for _, row := range cells {
+		for i, cell := range row {
+			if l := Width(cell); l > widths[i] {
 				widths[i] = l
 			}
 		}
 	}
+	return widths
+}
 
-	// Helper to draw a border line
-	makeBorder := func() string {
-		var b strings.Builder
+// ColumnWidths returns the display width each column takes in Render's
+// output, including its padding and right border.
+func ColumnWidths(columns []db.Column, rows [][]db.Value) []int {
+	widths := contentWidths(gridText(columns, rows))
+	for i := range widths {
+		widths[i] += 3
+	}
+	return widths
+}
+
+// borderLine draws +----+------+ for the given content widths.
+func borderLine(widths []int) string {
+	var b strings.Builder
+	b.WriteString("+")
+	for _, w := range widths {
+		b.WriteString(strings.Repeat("-", w+2))
 		b.WriteString("+")
-		for _, w := range widths {
-			b.WriteString(strings.Repeat("-", w+2))
-			b.WriteString("+")
-		}
-		b.WriteString("\n")
-		return b.String()
+	}
+	return b.String()
+}
+
+// Render builds an ASCII table from columns + rows. Numeric columns are
+// right-aligned.
+func Render(columns []db.Column, rows [][]db.Value) string {
+	if len(columns) == 0 {
+		return "(No columns)\n"
+	}
+
+	headers, cells := gridText(columns, rows)
+	widths := contentWidths(headers, cells)
+
+	makeBorder := func() string {
+		return borderLine(widths) + "\n"
 	}
 
 	var sb strings.Builder
@@ -48,9 +155,9 @@ func Render(columns []string, rows [][]string) string {
 
 	// Header
 	sb.WriteString("|")
-	for i, col := range columns {
+	for i, h := range headers {
 		sb.WriteString(" ")
-		sb.WriteString(fmt.Sprintf("%-*s", widths[i], col))
+		sb.WriteString(pad(h, widths[i], false))
 		sb.WriteString(" |")
 	}
 	sb.WriteString("\n")
@@ -59,17 +166,11 @@ func Render(columns []string, rows [][]string) string {
 	sb.WriteString(makeBorder())
 
 	// Rows
-	for _, row := range rows {
+	for _, row := range cells {
 		sb.WriteString("|")
-		for i := range columns {
-			var cell string
-			if i < len(row) {
-				cell = row[i]
-			} else {
-				cell = ""
-			}
+		for i, col := range columns {
 			sb.WriteString(" ")
-			sb.WriteString(fmt.Sprintf("%-*s", widths[i], cell))
+			sb.WriteString(pad(row[i], widths[i], col.Class == db.ClassNumeric))
 			sb.WriteString(" |")
 		}
 		sb.WriteString("\n")
@@ -81,7 +182,9 @@ func Render(columns []string, rows [][]string) string {
 	return sb.String()
 }
 
-// ApplyHorizontalScroll clips text horizontally based on offset and width.
+// ApplyHorizontalScroll clips text horizontally based on offset and width,
+// both measured in terminal columns. ANSI escape sequences are kept so
+// styling survives, and wide characters cut by an edge become spaces.
 func ApplyHorizontalScroll(s string, offset, width int) string {
 	if width <= 0 {
 		return s
@@ -91,22 +194,64 @@ func ApplyHorizontalScroll(s string, offset, width int) string {
 	}
 
 	lines := strings.Split(s, "\n")
-	var out []string
-	for _, line := range lines {
-		runes := []rune(line)
+	for i, line := range lines {
+		lines[i] = clipLine(line, offset, offset+width)
+	}
+	return strings.Join(lines, "\n")
+}
 
-		if offset >= len(runes) {
-			out = append(out, "")
+// clipLine keeps the display columns [from, to) of a single line.
+func clipLine(line string, from, to int) string {
+	var sb strings.Builder
+	col := 0
+	for len(line) > 0 {
+		if n := escapeLen(line); n > 0 {
+			sb.WriteString(line[:n])
+			line = line[n:]
 			continue
 		}
 
-		end := offset + width
-		if end > len(runes) {
-			end = len(runes)
-		}
+		cluster, rest, _, _ := uniseg.FirstGraphemeClusterInString(line, -1)
+		line = rest
+		w := Width(cluster)
 
-		out = append(out, string(runes[offset:end]))
+		switch {
+		case col >= from && col+w <= to:
+			sb.WriteString(cluster)
+		case col < to && col+w > from:
+			// partially visible wide character
+			start, end := max(col, from), min(col+w, to)
+			sb.WriteString(strings.Repeat(" ", end-start))
+		}
+		col += w
 	}
+	return sb.String()
+}
 
-	return strings.Join(out, "\n")
+// escapeLen returns the length of the ANSI escape sequence at the start of
+// s, or 0 if there is none.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' {
+		return 0
+	}
+	switch s[1] {
+	case '[': // CSI: parameters, then a final byte in 0x40–0x7e
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(s)
+	case ']': // OSC: terminated by BEL or ST
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(s)
+	}
+	return 2
 }
@@ -0,0 +1,103 @@
+package table
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hrutik5321/dbls/internal/db"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// mixedScript is a page with wide, zero-width and multi-line cell text.
+func mixedScript() ([]db.Column, [][]db.Value) {
+	columns := []db.Column{
+		{Name: "id", Class: db.ClassNumeric},
+		{Name: "名前", Class: db.ClassText},
+		{Name: "note", Class: db.ClassText},
+	}
+	rows := [][]db.Value{
+		{db.Text("1"), db.Text("東京タワー"), db.Text("plain ascii")},
+		{db.Text("22"), db.Text("👨‍👩‍👧 family"), db.Text("flag 🇯🇵 and 👍🏽")},
+		{db.Text("333"), db.Text("Café ñ"), db.Text("line one\nline two\tand a tab")},
+		{db.Text("4444"), db.Text("한국어"), db.NullValue},
+		{db.Text("5"), db.Text("bell\x07 and esc\x1b[31m"), db.Text("crlf\r\nend")},
+	}
+	return columns, rows
+}
+
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from %s\ngot:\n%s\nwant:\n%s", name, path, got, want)
+	}
+}
+
+// sameWidth checks that every line of a grid is as wide as the first, so
+// the borders line up.
+func sameWidth(t *testing.T, s string) {
+	t.Helper()
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for i, line := range lines {
+		if Width(line) != Width(lines[0]) {
+			t.Errorf("line %d is %d columns wide, want %d: %q", i, Width(line), Width(lines[0]), line)
+		}
+	}
+}
+
+func TestRenderMixedScript(t *testing.T) {
+	out := Render(mixedScript())
+	sameWidth(t, out)
+	golden(t, "mixed_script", out)
+}
+
+func TestMarkdownMixedScript(t *testing.T) {
+	out := Markdown(mixedScript())
+	sameWidth(t, out)
+	golden(t, "mixed_script_markdown", out)
+}
+
+func TestApplyHorizontalScroll(t *testing.T) {
+	grid := Render(mixedScript())
+	tests := []struct {
+		name           string
+		offset, length int
+	}{
+		{"scroll_start", 0, 20},
+		// starts inside the first wide character of the second column
+		{"scroll_cut_wide", 10, 17},
+		{"scroll_end", 30, 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := ApplyHorizontalScroll(grid, tt.offset, tt.length)
+			for i, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+				if w := Width(line); w > tt.length {
+					t.Errorf("line %d is %d columns wide, more than %d: %q", i, w, tt.length, line)
+				}
+			}
+			golden(t, tt.name, out)
+		})
+	}
+}
+
+func TestApplyHorizontalScrollKeepsEscapes(t *testing.T) {
+	line := "ab\x1b[1m東京\x1b[0mcd"
+	got := ApplyHorizontalScroll(line, 3, 3)
+	if want := "\x1b[1m 京\x1b[0m"; got != want {
+		t.Errorf("ApplyHorizontalScroll(%q, 3, 3) = %q, want %q", line, got, want)
+	}
+}
@@ -0,0 +1,109 @@
+package table
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/ui/theme"
+)
+
+// Highlight marks the cursor in a styled table; -1 means none. Match, if
+// set, is highlighted wherever it occurs in a cell, ignoring case.
+type Highlight struct {
+	Row   int
+	Col   int
+	Match string
+}
+
+// RenderStyled draws the same grid as Render using the theme: emphasized
+// header, zebra striping, dimmed NULLs, per-type colors and a highlighted
+// cursor row and cell. Layout and widths are identical to Render.
+func RenderStyled(columns []db.Column, rows [][]db.Value, th theme.Theme, hl Highlight) string {
+	if len(columns) == 0 {
+		return "(No columns)\n"
+	}
+
+	headers, cells := gridText(columns, rows)
+	widths := contentWidths(headers, cells)
+	border := th.Border.Render(borderLine(widths)) + "\n"
+	bar := th.Border.Render("|")
+
+	var sb strings.Builder
+
+	// Top border
+	sb.WriteString(border)
+
+	// Header
+	sb.WriteString(bar)
+	for i, h := range headers {
+		sb.WriteString(th.Header.Render(" " + pad(h, widths[i], false) + " "))
+		sb.WriteString(bar)
+	}
+	sb.WriteString("\n")
+
+	// Separator
+	sb.WriteString(border)
+
+	// Rows
+	for r, row := range cells {
+		rowStyle := lipgloss.NewStyle()
+		switch {
+		case r == hl.Row:
+			rowStyle = th.Selected
+		case r%2 == 1:
+			rowStyle = th.Zebra
+		}
+		rowBar := th.Border.Inherit(rowStyle).Render("|")
+
+		sb.WriteString(rowBar)
+		for i, col := range columns {
+			st := th.Class(col.Class)
+			if i < len(rows[r]) && rows[r][i].Null {
+				st = th.Null
+			}
+			if r == hl.Row && i == hl.Col {
+				st = th.Cursor.Inherit(st)
+			}
+			st = st.Inherit(rowStyle)
+
+			text := " " + pad(row[i], widths[i], col.Class == db.ClassNumeric) + " "
+			sb.WriteString(renderMatches(text, hl.Match, st, th.Match.Inherit(st)))
+			sb.WriteString(rowBar)
+		}
+		sb.WriteString("\n")
+	}
+
+	// Bottom border
+	sb.WriteString(border)
+
+	return sb.String()
+}
+
+// renderMatches renders text with st, and every case-insensitive
+// occurrence of match in it with hit.
+func renderMatches(text, match string, st, hit lipgloss.Style) string {
+	lower := strings.ToLower(text)
+	// byte offsets only carry over when lowering kept the length
+	if match == "" || len(lower) != len(text) {
+		return st.Render(text)
+	}
+	match = strings.ToLower(match)
+
+	var sb strings.Builder
+	for {
+		i := strings.Index(lower, match)
+		if i < 0 {
+			break
+		}
+		if i > 0 {
+			sb.WriteString(st.Render(text[:i]))
+		}
+		sb.WriteString(hit.Render(text[i : i+len(match)]))
+		text, lower = text[i+len(match):], lower[i+len(match):]
+	}
+	if text != "" {
+		sb.WriteString(st.Render(text))
+	}
+	return sb.String()
+}
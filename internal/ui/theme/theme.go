@@ -0,0 +1,179 @@
+package theme
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hrutik5321/dbls/internal/db"
+	"github.com/hrutik5321/dbls/internal/ui/jsonview"
+)
+
+// Theme holds every style the TUI draws with.
+type Theme struct {
+	Name string
+
+	// screens
+	Title  lipgloss.Style
+	Status lipgloss.Style
+	Help   lipgloss.Style
+	Error  lipgloss.Style
+	Banner lipgloss.Style // production warning
+
+	// result grid
+	Border   lipgloss.Style
+	Header   lipgloss.Style
+	Zebra    lipgloss.Style // every other data row
+	Selected lipgloss.Style // row under the cursor
+	Cursor   lipgloss.Style // cell under the cursor
+	Null     lipgloss.Style
+	Match    lipgloss.Style // search hits inside cells
+	Classes  map[db.TypeClass]lipgloss.Style
+
+	JSON jsonview.Styles
+}
+
+// Class returns the style for values of a type class.
+func (t Theme) Class(c db.TypeClass) lipgloss.Style {
+	if st, ok := t.Classes[c]; ok {
+		return st
+	}
+	return lipgloss.NewStyle()
+}
+
+func fg(c string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(c))
+}
+
+// banner is white on red in every color theme.
+var banner = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15")).Background(lipgloss.Color("160")).Padding(0, 1)
+
+// match is black text; each color theme picks a yellow background.
+var match = lipgloss.NewStyle().Foreground(lipgloss.Color("0"))
+
+var themes = map[string]Theme{
+	"dark": {
+		Name:     "dark",
+		Title:    fg("39").Bold(true),
+		Status:   fg("252"),
+		Help:     fg("243"),
+		Error:    fg("203").Bold(true),
+		Banner:   banner,
+		Border:   fg("240"),
+		Header:   fg("39").Bold(true),
+		Zebra:    lipgloss.NewStyle().Background(lipgloss.Color("235")),
+		Selected: lipgloss.NewStyle().Background(lipgloss.Color("238")),
+		Cursor:   lipgloss.NewStyle().Reverse(true),
+		Null:     fg("241").Italic(true),
+		Match:    match.Background(lipgloss.Color("220")),
+		Classes: map[db.TypeClass]lipgloss.Style{
+			db.ClassNumeric: fg("81"),
+			db.ClassBool:    fg("214"),
+			db.ClassTime:    fg("141"),
+			db.ClassJSON:    fg("150"),
+			db.ClassBinary:  fg("245"),
+		},
+		JSON: jsonview.DefaultStyles,
+	},
+	"light": {
+		Name:     "light",
+		Title:    fg("25").Bold(true),
+		Status:   fg("236"),
+		Help:     fg("244"),
+		Error:    fg("160").Bold(true),
+		Banner:   banner,
+		Border:   fg("250"),
+		Header:   fg("25").Bold(true),
+		Zebra:    lipgloss.NewStyle().Background(lipgloss.Color("254")),
+		Selected: lipgloss.NewStyle().Background(lipgloss.Color("252")),
+		Cursor:   lipgloss.NewStyle().Reverse(true),
+		Null:     fg("248").Italic(true),
+		Match:    match.Background(lipgloss.Color("228")),
+		Classes: map[db.TypeClass]lipgloss.Style{
+			db.ClassNumeric: fg("25"),
+			db.ClassBool:    fg("130"),
+			db.ClassTime:    fg("90"),
+			db.ClassJSON:    fg("28"),
+			db.ClassBinary:  fg("243"),
+		},
+		JSON: jsonview.Styles{
+			Key:    fg("25"),
+			String: fg("28"),
+			Number: fg("130"),
+			Bool:   fg("90"),
+			Null:   fg("244"),
+			Punct:  lipgloss.NewStyle(),
+			Fold:   fg("244").Italic(true),
+		},
+	},
+	"high-contrast": {
+		Name:     "high-contrast",
+		Title:    fg("15").Bold(true).Underline(true),
+		Status:   fg("15"),
+		Help:     fg("15"),
+		Error:    fg("9").Bold(true),
+		Banner:   banner,
+		Border:   fg("15"),
+		Header:   fg("15").Bold(true).Underline(true),
+		Zebra:    lipgloss.NewStyle(),
+		Selected: lipgloss.NewStyle().Bold(true),
+		Cursor:   lipgloss.NewStyle().Reverse(true),
+		Null:     fg("11").Italic(true),
+		Match:    match.Background(lipgloss.Color("11")),
+		Classes: map[db.TypeClass]lipgloss.Style{
+			db.ClassNumeric: fg("14"),
+			db.ClassBool:    fg("11"),
+			db.ClassTime:    fg("13"),
+			db.ClassJSON:    fg("10"),
+			db.ClassBinary:  fg("15"),
+		},
+		JSON: jsonview.Styles{
+			Key:    fg("14").Bold(true),
+			String: fg("10"),
+			Number: fg("11"),
+			Bool:   fg("13"),
+			Null:   fg("15").Italic(true),
+			Punct:  fg("15"),
+			Fold:   fg("15").Italic(true),
+		},
+	},
+	"no-color": {
+		Name:     "no-color",
+		Header:   lipgloss.NewStyle().Bold(true),
+		Selected: lipgloss.NewStyle().Bold(true),
+		Cursor:   lipgloss.NewStyle().Reverse(true),
+		Match:    lipgloss.NewStyle().Underline(true),
+		Error:    lipgloss.NewStyle().Bold(true),
+		Banner:   lipgloss.NewStyle().Bold(true).Reverse(true),
+	},
+}
+
+// Default is used when no theme is configured.
+const Default = "dark"
+
+// Names lists the available themes.
+func Names() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the named theme ("" means Default). Setting NO_COLOR in
+// the environment always selects "no-color", see https://no-color.org.
+func Resolve(name string) (Theme, error) {
+	if os.Getenv("NO_COLOR") != "" {
+		return themes["no-color"], nil
+	}
+	if name == "" {
+		name = Default
+	}
+	t, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (available: %v)", name, Names())
+	}
+	return t, nil
+}
@@ -1,25 +1,83 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/hrutik5321/dbls/internal/app"
+	"github.com/hrutik5321/dbls/internal/audit"
+	"github.com/hrutik5321/dbls/internal/config"
 	"github.com/hrutik5321/dbls/internal/db/postgres"
+	"github.com/hrutik5321/dbls/internal/ui/theme"
 )
 
 func main() {
-	// For now we always use Postgres. Later you can choose based on flags/env.
-	pg := postgres.New()
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run sets up the session and runs the program until it quits. Errors are
+// returned rather than fatal so the deferred cleanup still happens.
+func run() error {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		log.Printf("ignoring config: %v", err)
+	}
 
-	program := tea.NewProgram(app.New(pg))
+	var (
+		opts        app.Options
+		themeName   string
+		profileName string
+		readOnly    bool
+	)
+	flag.StringVar(&opts.TimeZone, "timezone", "", "time zone for timestamptz values, e.g. UTC or Asia/Kolkata (default: server setting)")
+	flag.StringVar(&themeName, "theme", settings.Theme, "color theme: "+strings.Join(theme.Names(), ", "))
+	flag.StringVar(&profileName, "profile", "", "connection profile from config.json to fill the form with")
+	flag.BoolVar(&readOnly, "read-only", false, "open a read-only session and disable every write")
+	flag.StringVar(&opts.AuditLog, "audit-log", "", "file to append every write to, as JSON lines (default: audit.jsonl in the config directory)")
+	flag.Parse()
 
-	if _, err := program.Run(); err != nil {
-		log.Fatalf("program failed: %v", err)
+	if opts.Theme, err = theme.Resolve(themeName); err != nil {
+		return err
+	}
+
+	if profileName != "" {
+		if opts.Profile, err = settings.Profile(profileName); err != nil {
+			return err
+		}
+		opts.ProfileName = profileName
 	}
+	opts.ReadOnly = readOnly || opts.Profile.ReadOnly
+
+	// without a config directory there is no default file: writes still
+	// run, and the banner warns that they are not logged
+	if opts.AuditLog == "" {
+		if dir, err := config.Dir(); err == nil {
+			opts.AuditLog = filepath.Join(dir, "audit.jsonl")
+		}
+	}
+	auditLog := audit.New(opts.AuditLog)
+	defer auditLog.Close()
+
+	// For now we always use Postgres. Later you can choose based on flags/env.
+	pg := audit.Wrap(postgres.New(), auditLog, opts.ProfileName)
 
 	// Make sure DB is closed.
-	if err := pg.Close(); err != nil {
-		log.Printf("error closing DB: %v", err)
+	defer func() {
+		if err := pg.Close(); err != nil {
+			log.Printf("error closing DB: %v", err)
+		}
+	}()
+
+	program := tea.NewProgram(app.New(pg, opts))
+
+	if _, err := program.Run(); err != nil {
+		return fmt.Errorf("program failed: %w", err)
 	}
+	return nil
 }